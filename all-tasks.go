@@ -0,0 +1,93 @@
+package tasq
+
+import (
+	"golang.org/x/net/context"
+	"sync"
+)
+
+const QDefaultParallelism = 4
+
+type QAllTasksOptions struct {
+	Filter      string
+	Sort        string
+	Parallelism int
+}
+
+func (svc *QService) AllTasks(ctx context.Context, opts *QAllTasksOptions) ([]*QTask, error) {
+	if opts == nil {
+		opts = &QAllTasksOptions{}
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism < 1 {
+		parallelism = QDefaultParallelism
+	}
+
+	tasklists, err := svc.Tasklists.List().Context(ctx).doAll()
+	if err != nil {
+		return nil, err
+	}
+
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]*QTask, len(tasklists))
+	semaphore := make(chan struct{}, parallelism)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i, tasklist := range tasklists {
+		wg.Add(1)
+		go func(i int, tasklist *QTaskList) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+			case <-listCtx.Done():
+				return
+			}
+			defer func() { <-semaphore }()
+
+			items, err := svc.Tasks.List(tasklist.Id).Context(listCtx).Filter(opts.Filter).doAll()
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+
+			for _, task := range items {
+				task.Tasklist = tasklist
+				for _, child := range task.Children {
+					child.Tasklist = tasklist
+				}
+			}
+			results[i] = items
+		}(i, tasklist)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	all := make([]*QTask, 0)
+	for _, items := range results {
+		all = append(all, items...)
+	}
+
+	// Positions are only comparable within a single tasklist,
+	// which each result already is sorted by
+	if opts.Sort != QPositionSort {
+		sortTasks(all, opts.Sort)
+	}
+
+	return all, nil
+}
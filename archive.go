@@ -0,0 +1,166 @@
+package tasq
+
+import (
+	"encoding/json"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"os"
+	"sync"
+	"time"
+)
+
+type QArchivedTask struct {
+	Tasklist   string        `json:"tasklist"`
+	ArchivedAt string        `json:"archivedAt"`
+	Task       *tasks.Task   `json:"task"`
+	Children   []*tasks.Task `json:"children,omitempty"`
+}
+
+// Receives completed tasks before they are removed from their tasklist,
+// returning an error leaves the tasks in place
+type QArchiveSink interface {
+	Archive(ctx context.Context, items []*QArchivedTask) error
+}
+
+type QArchiveOptions struct {
+	// Only archive tasks completed at least this long ago
+	OlderThan time.Duration
+}
+
+// Writes completed tasks with all of their subtasks to the sink, then deletes
+// exactly the archived tasks, tasks missing from Items are left untouched
+func (tasks *QTasks) Archive(ctx context.Context, sink QArchiveSink, opts *QArchiveOptions) ([]*QArchivedTask, error) {
+	if opts == nil {
+		opts = &QArchiveOptions{}
+	}
+
+	now := time.Now().UTC()
+	selected := selectTasks(tasks.Items, func(task *QTask) bool {
+		return task.Status == QCompletedStatus && completedBefore(task, now.Add(-opts.OlderThan))
+	})
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	// Items may only hold the subtasks matching a filter, deleting
+	// a parent deletes all of them so every one of them is archived
+	children, err := tasks.allSubtasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make([]*QArchivedTask, 0)
+	for _, task := range selected {
+		item := &QArchivedTask{
+			Tasklist:   tasks.ctx.tasklistid,
+			ArchivedAt: now.Format(time.RFC3339),
+			Task:       task.Task,
+		}
+		for _, child := range children[task.Id] {
+			item.Children = append(item.Children, child.Task)
+		}
+		archived = append(archived, item)
+	}
+
+	if err := sink.Archive(ctx, archived); err != nil {
+		return nil, err
+	}
+
+	service := tasks.ctx.service
+	report := tasks.bulk(ctx, selected, func(ctx context.Context, task *QTask) (*QTask, error) {
+		return nil, service.Delete(task.ctx.tasklistid, task.Id).Context(ctx).Do()
+	})
+
+	return archived, report.Err()
+}
+
+func (tasks *QTasks) allSubtasks(ctx context.Context) (map[string][]*QTask, error) {
+	items, err := tasks.ctx.service.List(tasks.ctx.tasklistid).Context(ctx).ShowHidden(true).ShowCompleted(true).doAll()
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]*QTask)
+	for _, item := range items {
+		children[item.Id] = item.Children
+	}
+
+	return children, nil
+}
+
+func completedBefore(task *QTask, threshold time.Time) bool {
+	timestamp := task.Updated
+	if task.Completed != nil {
+		timestamp = *task.Completed
+	}
+
+	completed, err := Time(timestamp)
+	if err != nil {
+		return false
+	}
+
+	return !completed.After(threshold)
+}
+
+// Appends each archived task as a line of JSON
+type QFileArchive struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileArchive(path string) *QFileArchive {
+	return &QFileArchive{path: path}
+}
+
+func (archive *QFileArchive) Archive(ctx context.Context, items []*QArchivedTask) error {
+	archive.mu.Lock()
+	defer archive.mu.Unlock()
+
+	file, err := os.OpenFile(archive.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Copies archived tasks with their subtasks into another tasklist
+type QTasklistArchive struct {
+	service    *QTasksService
+	tasklistid string
+}
+
+func NewTasklistArchive(service *QTasksService, tasklistid string) *QTasklistArchive {
+	return &QTasklistArchive{service: service, tasklistid: tasklistid}
+}
+
+func (archive *QTasklistArchive) Archive(ctx context.Context, items []*QArchivedTask) error {
+	for _, item := range items {
+		task := &QTask{
+			Task: item.Task,
+			ctx: &QTaskCallContext{
+				service:    archive.service,
+				tasklistid: item.Tasklist,
+			},
+		}
+
+		children := make([]*QTask, 0)
+		for _, child := range item.Children {
+			children = append(children, &QTask{Task: child, ctx: task.ctx})
+		}
+
+		if _, err := task.copyTree(ctx, archive.tasklistid, "", "", children); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,385 @@
+package tasq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/tasks/v1"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const QMaxBatchSize = 1000
+
+type QBatchResult struct {
+	Task *QTask
+	Err  error
+}
+
+type QBatch struct {
+	svc *QService
+	ops []*batchOp
+}
+
+type batchOp struct {
+	method     string
+	path       string
+	query      url.Values
+	body       *tasks.Task
+	ifMatch    string
+	tasklistid string
+	planned    *QPlannedCall
+}
+
+func (svc *QService) Batch() *QBatch {
+	return &QBatch{svc: svc}
+}
+
+func (batch *QBatch) Insert(call *QTasksInsertCall) *QBatch {
+	query := url.Values{}
+	if call.parent != "" {
+		query.Set("parent", call.parent)
+	}
+	if call.previous != "" {
+		query.Set("previous", call.previous)
+	}
+
+	batch.ops = append(batch.ops, &batchOp{
+		method:     http.MethodPost,
+		path:       taskPath(call.ctx.tasklistid, ""),
+		query:      query,
+		body:       call.task.Task,
+		tasklistid: call.ctx.tasklistid,
+		planned: &QPlannedCall{
+			Method:   QTasksInsertMethod,
+			Tasklist: call.ctx.tasklistid,
+			Parent:   call.parent,
+			Previous: call.previous,
+			Body:     call.task.Task,
+		},
+	})
+	return batch
+}
+
+func (batch *QBatch) Patch(call *QTasksPatchCall) *QBatch {
+	ifMatch := call.ifMatch
+	if ifMatch == "" && call.ctx.service.config.etags {
+		ifMatch = call.task.Etag
+	}
+
+	batch.ops = append(batch.ops, &batchOp{
+		method:     http.MethodPatch,
+		path:       taskPath(call.ctx.tasklistid, call.taskid),
+		body:       call.task.Task,
+		ifMatch:    ifMatch,
+		tasklistid: call.ctx.tasklistid,
+		planned: &QPlannedCall{
+			Method:   QTasksPatchMethod,
+			Tasklist: call.ctx.tasklistid,
+			Task:     call.taskid,
+			Body:     call.task.Task,
+		},
+	})
+	return batch
+}
+
+func (batch *QBatch) Delete(call *QTasksDeleteCall) *QBatch {
+	batch.ops = append(batch.ops, &batchOp{
+		method:     http.MethodDelete,
+		path:       taskPath(call.ctx.tasklistid, call.taskid),
+		tasklistid: call.ctx.tasklistid,
+		planned: &QPlannedCall{
+			Method:   QTasksDeleteMethod,
+			Tasklist: call.ctx.tasklistid,
+			Task:     call.taskid,
+		},
+	})
+	return batch
+}
+
+func (batch *QBatch) Move(call *QTasksMoveCall) *QBatch {
+	query := url.Values{}
+	if call.parent != "" {
+		query.Set("parent", call.parent)
+	}
+	if call.previous != "" {
+		query.Set("previous", call.previous)
+	}
+
+	batch.ops = append(batch.ops, &batchOp{
+		method:     http.MethodPost,
+		path:       taskPath(call.ctx.tasklistid, call.taskid) + "/move",
+		query:      query,
+		tasklistid: call.ctx.tasklistid,
+		planned: &QPlannedCall{
+			Method:   QTasksMoveMethod,
+			Tasklist: call.ctx.tasklistid,
+			Task:     call.taskid,
+			Parent:   call.parent,
+			Previous: call.previous,
+		},
+	})
+	return batch
+}
+
+func (batch *QBatch) Len() int {
+	return len(batch.ops)
+}
+
+// Results are in the order the calls were added, a failed call only sets
+// the Err of its own result. The returned error is set when a whole batch
+// could not be sent, results of batches not sent are left nil
+func (batch *QBatch) Do(ctx context.Context) ([]*QBatchResult, error) {
+	results := make([]*QBatchResult, len(batch.ops))
+
+	if plan := batch.svc.config.plan; plan != nil {
+		for i, op := range batch.ops {
+			results[i] = batch.dryRun(plan, op)
+		}
+		return results, nil
+	}
+
+	journal := batch.svc.config.journal
+	entries, err := batch.journalEntries(ctx)
+	if err != nil {
+		return results, err
+	}
+
+	for start := 0; start < len(batch.ops); start += QMaxBatchSize {
+		end := start + QMaxBatchSize
+		if end > len(batch.ops) {
+			end = len(batch.ops)
+		}
+
+		if err := batch.send(ctx, batch.ops[start:end], results[start:end]); err != nil {
+			return results, err
+		}
+
+		for i := start; i < end; i++ {
+			if results[i].Err != nil {
+				continue
+			}
+
+			entry := entries[i]
+			if batch.ops[i].planned.Method == QTasksInsertMethod {
+				entry = &QJournalEntry{
+					Method:   QTasksInsertMethod,
+					Tasklist: batch.ops[i].tasklistid,
+					Task:     results[i].Task.Id,
+				}
+			}
+			if err := journal.append(entry); err != nil {
+				return results, err
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// Snapshots for the journal taken from one listing per tasklist,
+// instead of a request for each call
+func (batch *QBatch) journalEntries(ctx context.Context) ([]*QJournalEntry, error) {
+	entries := make([]*QJournalEntry, len(batch.ops))
+	if batch.svc.config.journal == nil {
+		return entries, nil
+	}
+
+	listings := make(map[string][]*QTask)
+	for i, op := range batch.ops {
+		if op.planned.Method == QTasksInsertMethod {
+			continue
+		}
+
+		items, ok := listings[op.tasklistid]
+		if !ok {
+			var err error
+			items, err = batch.svc.Tasks.List(op.tasklistid).Context(ctx).ShowHidden(true).doAll()
+			if err != nil {
+				return nil, err
+			}
+			listings[op.tasklistid] = items
+		}
+
+		entries[i] = taskChangeEntry(op.planned.Method, op.tasklistid, op.planned.Task, items)
+	}
+
+	return entries, nil
+}
+
+func (batch *QBatch) send(ctx context.Context, ops []*batchOp, results []*QBatchResult) error {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for i, op := range ops {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", "application/http")
+		header.Set("Content-ID", fmt.Sprintf("<item%d>", i))
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if err := op.write(part); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, batch.svc.BasePath+"batch", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+
+	res, err := batch.svc.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if err := googleapi.CheckResponse(res); err != nil {
+		return err
+	}
+
+	_, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+
+	reader := multipart.NewReader(res.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		i, err := batchIndex(part.Header.Get("Content-ID"))
+		if err != nil || i >= len(ops) {
+			continue
+		}
+
+		results[i] = batch.readResult(part, ops[i])
+	}
+
+	for i := range results {
+		if results[i] == nil {
+			results[i] = &QBatchResult{Err: fmt.Errorf("tasq: no response for batched call %d", i)}
+		}
+
+		// Reported as a single call would report it
+		if isPreconditionFailed(results[i].Err) {
+			local := &QTask{Task: ops[i].body}
+			results[i].Err = batch.svc.Tasks.conflict(ctx, ops[i].tasklistid, ops[i].planned.Task, local, results[i].Err)
+		}
+	}
+
+	return nil
+}
+
+func (batch *QBatch) readResult(part io.Reader, op *batchOp) *QBatchResult {
+	res, err := http.ReadResponse(bufio.NewReader(part), nil)
+	if err != nil {
+		return &QBatchResult{Err: err}
+	}
+	defer res.Body.Close()
+
+	if err := googleapi.CheckResponse(res); err != nil {
+		return &QBatchResult{Err: err}
+	}
+	if op.method == http.MethodDelete {
+		return &QBatchResult{}
+	}
+
+	task := &tasks.Task{}
+	if err := json.NewDecoder(res.Body).Decode(task); err != nil {
+		return &QBatchResult{Err: err}
+	}
+
+	return &QBatchResult{Task: &QTask{
+		Task: task,
+		ctx: &QTaskCallContext{
+			service:    batch.svc.Tasks,
+			tasklistid: op.tasklistid,
+		},
+		base: snapshotTask(task),
+	}}
+}
+
+func (batch *QBatch) dryRun(plan *QPlan, op *batchOp) *QBatchResult {
+	if op.method == http.MethodDelete {
+		plan.record(op.planned)
+		return &QBatchResult{}
+	}
+
+	task := plan.synthesizeTask(op.planned, op.body)
+	return &QBatchResult{Task: &QTask{
+		Task: task,
+		ctx: &QTaskCallContext{
+			service:    batch.svc.Tasks,
+			tasklistid: op.tasklistid,
+		},
+		base: snapshotTask(task),
+	}}
+}
+
+func (op *batchOp) write(w io.Writer) error {
+	target := op.path
+	if len(op.query) > 0 {
+		target += "?" + op.query.Encode()
+	}
+
+	if _, err := fmt.Fprintf(w, "%s %s HTTP/1.1\r\n", op.method, target); err != nil {
+		return err
+	}
+	if op.ifMatch != "" {
+		if _, err := fmt.Fprintf(w, "If-Match: %s\r\n", op.ifMatch); err != nil {
+			return err
+		}
+	}
+
+	if op.body == nil {
+		_, err := io.WriteString(w, "\r\n")
+		return err
+	}
+
+	body, err := json.Marshal(op.body)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "Content-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func taskPath(tasklistid, taskid string) string {
+	path := "/tasks/v1/lists/" + url.PathEscape(tasklistid) + "/tasks"
+	if taskid != "" {
+		path += "/" + url.PathEscape(taskid)
+	}
+
+	return path
+}
+
+// Responses are identified by <response-itemN>
+func batchIndex(contentID string) (int, error) {
+	id := strings.Trim(contentID, "<>")
+	id = strings.TrimPrefix(id, "response-")
+	id = strings.TrimPrefix(id, "item")
+
+	return strconv.Atoi(id)
+}
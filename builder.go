@@ -0,0 +1,109 @@
+package tasq
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	QMaxTitleLength = 1024
+	QMaxNotesLength = 8192
+)
+
+var ErrTitleRequired = errors.New("tasq: task title is required")
+
+type QTaskBuilder struct {
+	service    *QTasksService
+	tasklistid string
+	task       *tasks.Task
+	parent     string
+	previous   string
+	dueString  string
+}
+
+func (service *QTasksService) New(tasklistid string) *QTaskBuilder {
+	return &QTaskBuilder{
+		service:    service,
+		tasklistid: tasklistid,
+		task:       &tasks.Task{},
+	}
+}
+
+func (builder *QTaskBuilder) Title(title string) *QTaskBuilder {
+	builder.task.Title = title
+	return builder
+}
+
+func (builder *QTaskBuilder) Notes(notes string) *QTaskBuilder {
+	builder.task.Notes = notes
+	return builder
+}
+
+func (builder *QTaskBuilder) Due(due time.Time) *QTaskBuilder {
+	builder.task.Due = dueDate(due)
+	return builder
+}
+
+// Parsed with DefaultDateParser when the task is validated, e.g. "next fri"
+func (builder *QTaskBuilder) DueString(phrase string) *QTaskBuilder {
+	builder.dueString = phrase
+	return builder
+}
+
+func (builder *QTaskBuilder) Under(parent string) *QTaskBuilder {
+	builder.parent = parent
+	return builder
+}
+
+func (builder *QTaskBuilder) After(previous string) *QTaskBuilder {
+	builder.previous = previous
+	return builder
+}
+
+func (builder *QTaskBuilder) Validate() error {
+	if strings.TrimSpace(builder.task.Title) == "" {
+		return ErrTitleRequired
+	}
+	if n := utf8.RuneCountInString(builder.task.Title); n > QMaxTitleLength {
+		return fmt.Errorf("tasq: task title is %d characters, maximum is %d", n, QMaxTitleLength)
+	}
+	if n := utf8.RuneCountInString(builder.task.Notes); n > QMaxNotesLength {
+		return fmt.Errorf("tasq: task notes are %d characters, maximum is %d", n, QMaxNotesLength)
+	}
+	if builder.dueString != "" {
+		date, err := DefaultDateParser.Parse(builder.dueString)
+		if err != nil {
+			return err
+		}
+		builder.task.Due = dueDate(date.In(time.UTC))
+	}
+
+	return nil
+}
+
+func (builder *QTaskBuilder) Create(ctx context.Context) (*QTask, error) {
+	if err := builder.Validate(); err != nil {
+		return nil, err
+	}
+
+	call := builder.service.Insert(builder.tasklistid, &QTask{Task: builder.task}).Context(ctx)
+	if builder.parent != "" {
+		call.Parent(builder.parent)
+	}
+	if builder.previous != "" {
+		call.Previous(builder.previous)
+	}
+
+	return call.Do()
+}
+
+// Google Tasks only stores the date portion of due
+func dueDate(due time.Time) string {
+	year, month, day := due.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+}
@@ -0,0 +1,276 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/googleapi"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	QMaxRetries = 5
+
+	initialBackoff = 500 * time.Millisecond
+)
+
+type QTaskFilter func(task *QTask) bool
+
+type QTaskResult struct {
+	Task   *QTask
+	Result *QTask
+	Err    error
+}
+
+type QBulkReport struct {
+	Results []*QTaskResult
+}
+
+func (report *QBulkReport) Failed() []*QTaskResult {
+	failed := make([]*QTaskResult, 0)
+	for _, result := range report.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+func (report *QBulkReport) Err() error {
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("tasq: %d of %d tasks failed, first error: %v", len(failed), len(report.Results), failed[0].Err)
+}
+
+// Concurrency bounds the number of requests in flight during bulk
+// operations, requestsPerSecond of zero disables rate limiting
+func (svc *QService) SetBulkLimits(concurrency int, requestsPerSecond float64) {
+	svc.config.concurrency = concurrency
+	svc.config.limiter = newRateLimiter(requestsPerSecond)
+}
+
+func (tasks *QTasks) CompleteAll(ctx context.Context) *QBulkReport {
+	selected := make([]*QTask, 0)
+	for _, task := range flattenTasks(tasks.Items) {
+		if task.Status != QCompletedStatus {
+			selected = append(selected, task)
+		}
+	}
+
+	return tasks.bulk(ctx, selected, func(ctx context.Context, task *QTask) (*QTask, error) {
+		return task.patchStatus(ctx, QCompletedStatus)
+	})
+}
+
+func (tasks *QTasks) DeleteWhere(ctx context.Context, filter QTaskFilter) *QBulkReport {
+	return tasks.bulk(ctx, selectTasks(tasks.Items, filter), func(ctx context.Context, task *QTask) (*QTask, error) {
+		return nil, task.ctx.service.Delete(task.ctx.tasklistid, task.Id).Context(ctx).Do()
+	})
+}
+
+func (tasks *QTasks) PatchWhere(ctx context.Context, filter QTaskFilter, mutate func(task *QTask)) *QBulkReport {
+	selected := make([]*QTask, 0)
+	for _, task := range flattenTasks(tasks.Items) {
+		if filter(task) {
+			selected = append(selected, task)
+		}
+	}
+
+	// Mutated up front, retries after rate limiting only repeat the patch
+	for _, task := range selected {
+		mutate(task)
+	}
+
+	return tasks.bulk(ctx, selected, func(ctx context.Context, task *QTask) (*QTask, error) {
+		return task.ctx.service.Patch(task.ctx.tasklistid, task.Id, task).Context(ctx).Do()
+	})
+}
+
+// Moves the tasks one after another so they keep their order in the other
+// tasklist. Moves are not retried, a failed move may have copied part of a tree
+func (tasks *QTasks) MoveAllTo(ctx context.Context, tasklistid string) *QBulkReport {
+	report := &QBulkReport{Results: make([]*QTaskResult, len(tasks.Items))}
+	for i, task := range tasks.Items {
+		report.Results[i] = &QTaskResult{Task: task}
+	}
+
+	if len(tasks.Items) == 0 || tasklistid == tasks.ctx.tasklistid {
+		for _, result := range report.Results {
+			result.Result = result.Task
+		}
+		return report
+	}
+
+	// Items may only hold the subtasks matching a filter
+	children, err := tasks.allSubtasks(ctx)
+	if err != nil {
+		for _, result := range report.Results {
+			result.Err = err
+		}
+		return report
+	}
+
+	previous := ""
+	for _, result := range report.Results {
+		if result.Err = tasks.ctx.service.config.limiter.wait(ctx); result.Err != nil {
+			continue
+		}
+
+		result.Result, result.Err = result.Task.moveToListAfter(ctx, tasklistid, previous, children[result.Task.Id])
+		if result.Err == nil {
+			previous = result.Result.Id
+		}
+	}
+
+	return report
+}
+
+func (tasks *QTasks) bulk(ctx context.Context, items []*QTask, op func(ctx context.Context, task *QTask) (*QTask, error)) *QBulkReport {
+	config := tasks.ctx.service.config
+
+	concurrency := config.concurrency
+	if concurrency < 1 {
+		concurrency = QDefaultParallelism
+	}
+
+	report := &QBulkReport{Results: make([]*QTaskResult, len(items))}
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, task := range items {
+		report.Results[i] = &QTaskResult{Task: task}
+
+		wg.Add(1)
+		go func(result *QTaskResult) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return
+			}
+			defer func() { <-semaphore }()
+
+			result.Result, result.Err = withRetry(ctx, config.limiter, func() (*QTask, error) {
+				return op(ctx, result.Task)
+			})
+		}(report.Results[i])
+	}
+	wg.Wait()
+
+	return report
+}
+
+func withRetry(ctx context.Context, limiter *rateLimiter, call func() (*QTask, error)) (*QTask, error) {
+	backoff := initialBackoff
+
+	for attempt := 0; ; attempt++ {
+		if err := limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		result, err := call()
+		if !isRateLimited(err) || attempt == QMaxRetries {
+			return result, err
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func isRateLimited(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+	if apiErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+
+	if apiErr.Code == http.StatusForbidden {
+		for _, item := range apiErr.Errors {
+			if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Selected parents are deleted along with their subtasks,
+// so their subtasks are not selected separately
+func selectTasks(items []*QTask, filter QTaskFilter) []*QTask {
+	selected := make([]*QTask, 0)
+	for _, task := range items {
+		if filter(task) {
+			selected = append(selected, task)
+			continue
+		}
+
+		selected = append(selected, selectTasks(task.Children, filter)...)
+	}
+
+	return selected
+}
+
+func flattenTasks(items []*QTask) []*QTask {
+	flattened := make([]*QTask, 0)
+	for _, task := range items {
+		flattened = append(flattened, task)
+		flattened = append(flattened, flattenTasks(task.Children)...)
+	}
+
+	return flattened
+}
+
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
+
+func (limiter *rateLimiter) wait(ctx context.Context) error {
+	if limiter == nil {
+		return nil
+	}
+
+	limiter.mu.Lock()
+	now := time.Now()
+	if limiter.next.Before(now) {
+		limiter.next = now
+	}
+	delay := limiter.next.Sub(now)
+	limiter.next = limiter.next.Add(limiter.interval)
+	limiter.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
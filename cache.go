@@ -0,0 +1,204 @@
+package tasq
+
+import (
+	"encoding/json"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/tasks/v1"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// Stores raw list and get responses, implementations must be safe for concurrent use
+type QCacheStorage interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte) error
+	Delete(key string) error
+}
+
+// List and get calls of tasks are revalidated with the etag of the
+// stored response, which is served again when the server reports
+// it has not been modified. Calls given an explicit IfNoneMatch bypass the cache
+func (svc *QService) EnableCache(storage QCacheStorage) {
+	svc.config.cache = storage
+}
+
+func (svc *QService) DisableCache() {
+	svc.config.cache = nil
+}
+
+type QMemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string][]byte
+}
+
+func NewMemoryCache() *QMemoryCache {
+	return &QMemoryCache{entries: make(map[string][]byte)}
+}
+
+func (cache *QMemoryCache) Get(key string) ([]byte, bool, error) {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+
+	value, ok := cache.entries[key]
+	return value, ok, nil
+}
+
+func (cache *QMemoryCache) Set(key string, value []byte) error {
+	cache.mu.Lock()
+	cache.entries[key] = value
+	cache.mu.Unlock()
+	return nil
+}
+
+func (cache *QMemoryCache) Delete(key string) error {
+	cache.mu.Lock()
+	delete(cache.entries, key)
+	cache.mu.Unlock()
+	return nil
+}
+
+// Keeps every entry in memory and rewrites the whole file on each change
+type QFileCache struct {
+	memory *QMemoryCache
+	mu     sync.Mutex
+	path   string
+}
+
+func NewFileCache(path string) (*QFileCache, error) {
+	cache := &QFileCache{memory: NewMemoryCache(), path: path}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &cache.memory.entries); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+func (cache *QFileCache) Get(key string) ([]byte, bool, error) {
+	return cache.memory.Get(key)
+}
+
+func (cache *QFileCache) Set(key string, value []byte) error {
+	cache.memory.Set(key, value)
+	return cache.save()
+}
+
+func (cache *QFileCache) Delete(key string) error {
+	cache.memory.Delete(key)
+	return cache.save()
+}
+
+func (cache *QFileCache) save() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.memory.mu.RLock()
+	data, err := json.Marshal(cache.memory.entries)
+	cache.memory.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(cache.path, data)
+}
+
+// Written to a temporary file first so a crash cannot leave it half written
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (call *QTasksListCall) cachedDo(opts ...googleapi.CallOption) (*tasks.Tasks, error) {
+	storage := call.ctx.service.config.cache
+	if storage == nil || call.ifNoneMatch {
+		return call.TasksListCall.Do(opts...)
+	}
+
+	// The filter stands in for the bounds it computes from the clock
+	key := "lists/" + call.ctx.tasklistid + "/tasks?" + call.params.Encode() + "#" + call.filter
+	cached := &tasks.Tasks{}
+	ok, err := loadCached(storage, key, cached)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		cached = &tasks.Tasks{}
+	}
+
+	// Reset when there is no entry, doAll reuses the call for every page
+	call.TasksListCall.IfNoneMatch(cached.Etag)
+
+	result, err := call.TasksListCall.Do(opts...)
+	if googleapi.IsNotModified(err) {
+		return cached, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, storeCached(storage, key, result)
+}
+
+func (call *QTasksGetCall) cachedDo(opts ...googleapi.CallOption) (*tasks.Task, error) {
+	storage := call.ctx.service.config.cache
+	if storage == nil || call.ifNoneMatch {
+		return call.TasksGetCall.Do(opts...)
+	}
+
+	key := "lists/" + call.ctx.tasklistid + "/tasks/" + call.taskid + "?fields=" + call.fields
+	cached := &tasks.Task{}
+	ok, err := loadCached(storage, key, cached)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		cached = &tasks.Task{}
+	}
+	call.TasksGetCall.IfNoneMatch(cached.Etag)
+
+	result, err := call.TasksGetCall.Do(opts...)
+	if googleapi.IsNotModified(err) {
+		return cached, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, storeCached(storage, key, result)
+}
+
+func loadCached(storage QCacheStorage, key string, value interface{}) (bool, error) {
+	data, ok, err := storage.Get(key)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	// Entries that cannot be decoded are treated as missing
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func storeCached(storage QCacheStorage, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return storage.Set(key, data)
+}
@@ -0,0 +1,103 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/googleapi"
+	"net/http"
+)
+
+// Remote is nil when the current version could not be fetched
+type QConflictError struct {
+	Err    error
+	Local  *QTask
+	Remote *QTask
+
+	// Taken from the call, the body of a patch may not carry an id
+	taskid string
+}
+
+func (e *QConflictError) Error() string {
+	return fmt.Sprintf("tasq: task %s was modified remotely: %v", e.taskid, e.Err)
+}
+
+func (e *QConflictError) Unwrap() error {
+	return e.Err
+}
+
+// Remote is nil when the current version could not be fetched
+type QTasklistConflictError struct {
+	Err    error
+	Local  *QTaskList
+	Remote *QTaskList
+
+	// Taken from the call, the body of a patch may not carry an id
+	tasklistid string
+}
+
+func (e *QTasklistConflictError) Error() string {
+	return fmt.Sprintf("tasq: tasklist %s was modified remotely: %v", e.tasklistid, e.Err)
+}
+
+func (e *QTasklistConflictError) Unwrap() error {
+	return e.Err
+}
+
+// When enabled, Update and Patch only succeed if the
+// remote object still has the same etag as the local one
+func (svc *QService) SetOptimisticConcurrency(enabled bool) {
+	svc.config.etags = enabled
+}
+
+func (tasks *QTasksService) conflict(ctx context.Context, tasklistid, taskid string, local *QTask, err error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	conflict := &QConflictError{
+		Err:    err,
+		Local:  local,
+		taskid: taskid,
+	}
+
+	remote, getErr := tasks.Get(tasklistid, taskid).Context(ctx).Do()
+	if getErr == nil {
+		conflict.Remote = remote
+	}
+
+	return conflict
+}
+
+func (lists *QTasklistsService) conflict(ctx context.Context, tasklistid string, local *QTaskList, err error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	conflict := &QTasklistConflictError{
+		Err:        err,
+		Local:      local,
+		tasklistid: tasklistid,
+	}
+
+	remote, getErr := lists.Get(tasklistid).Context(ctx).Do()
+	if getErr == nil {
+		conflict.Remote = remote
+	}
+
+	return conflict
+}
+
+func setIfMatch(header http.Header, config *serviceConfig, ifMatch string, entityTag string) {
+	if ifMatch == "" && config.etags {
+		ifMatch = entityTag
+	}
+
+	if ifMatch != "" {
+		header.Set("If-Match", ifMatch)
+	}
+}
+
+func isPreconditionFailed(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	return ok && apiErr.Code == http.StatusPreconditionFailed
+}
@@ -0,0 +1,101 @@
+package tasq
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/option"
+	"google.golang.org/api/tasks/v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Service talking to handler instead of the Tasks API
+func newTestService(t *testing.T, handler http.HandlerFunc) *QService {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	service, err := tasks.NewService(context.Background(), option.WithEndpoint(server.URL+"/"), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &serviceConfig{}
+	svc := &QService{
+		Service:   service,
+		Tasklists: &QTasklistsService{TasklistsService: service.Tasklists, config: config},
+		Tasks:     &QTasksService{TasksService: service.Tasks, config: config},
+		config:    config,
+	}
+	svc.Tasklists.tasks = svc.Tasks
+
+	return svc
+}
+
+func preconditionFailed(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusPreconditionFailed)
+	fmt.Fprint(w, `{"error":{"code":412,"message":"Precondition Failed"}}`)
+}
+
+func TestSparsePatchConflict(t *testing.T) {
+	for _, merge := range []bool{false, true} {
+		svc := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPatch && r.Header.Get("If-Match") == "old":
+				preconditionFailed(w)
+			case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/lists/list/tasks/task"):
+				fmt.Fprint(w, `{"id":"task","etag":"new","title":"remote"}`)
+			default:
+				t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		svc.SetOptimisticConcurrency(true)
+		svc.SetAutoMerge(merge)
+
+		patch := &QTask{Task: &tasks.Task{Title: "local", Etag: "old"}}
+		_, err := svc.Tasks.Patch("list", "task", patch).Do()
+
+		var conflict *QConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("merge %v: got %v, want a conflict", merge, err)
+		}
+		if conflict.Remote == nil || conflict.Remote.Id != "task" {
+			t.Errorf("merge %v: remote copy was not fetched: %+v", merge, conflict.Remote)
+		}
+		if !strings.Contains(err.Error(), "task task was modified") {
+			t.Errorf("merge %v: got message %q", merge, err)
+		}
+	}
+}
+
+func TestSparseTasklistPatchConflict(t *testing.T) {
+	svc := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPatch && r.Header.Get("If-Match") == "old":
+			preconditionFailed(w)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/lists/list"):
+			fmt.Fprint(w, `{"id":"list","etag":"new","title":"remote"}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	svc.SetOptimisticConcurrency(true)
+
+	patch := &QTaskList{TaskList: &tasks.TaskList{Title: "local", Etag: "old"}}
+	_, err := svc.Tasklists.Patch("list", patch).Do()
+
+	var conflict *QTasklistConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got %v, want a conflict", err)
+	}
+	if conflict.Remote == nil || conflict.Remote.Id != "list" {
+		t.Errorf("remote copy was not fetched: %+v", conflict.Remote)
+	}
+	if !strings.Contains(err.Error(), "tasklist list was modified") {
+		t.Errorf("got message %q", err)
+	}
+}
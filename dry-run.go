@@ -0,0 +1,152 @@
+package tasq
+
+import (
+	"fmt"
+	"google.golang.org/api/tasks/v1"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	QTasksClearMethod      = "tasks.clear"
+	QTasksDeleteMethod     = "tasks.delete"
+	QTasksInsertMethod     = "tasks.insert"
+	QTasksMoveMethod       = "tasks.move"
+	QTasksPatchMethod      = "tasks.patch"
+	QTasksUpdateMethod     = "tasks.update"
+	QTasklistsDeleteMethod = "tasklists.delete"
+	QTasklistsInsertMethod = "tasklists.insert"
+	QTasklistsPatchMethod  = "tasklists.patch"
+	QTasklistsUpdateMethod = "tasklists.update"
+)
+
+type QPlannedCall struct {
+	Method   string
+	Tasklist string
+	Task     string
+	Parent   string
+	Previous string
+
+	// Either *tasks.Task or *tasks.TaskList, nil when the call has no body
+	Body interface{}
+}
+
+func (call *QPlannedCall) String() string {
+	target := call.Tasklist
+	if call.Task != "" {
+		target += "/" + call.Task
+	}
+
+	description := fmt.Sprintf("%s %s", call.Method, target)
+	if call.Parent != "" {
+		description += " parent=" + call.Parent
+	}
+	if call.Previous != "" {
+		description += " previous=" + call.Previous
+	}
+
+	return description
+}
+
+type QPlan struct {
+	mu     sync.Mutex
+	calls  []*QPlannedCall
+	nextId int
+}
+
+func (plan *QPlan) Calls() []*QPlannedCall {
+	plan.mu.Lock()
+	defer plan.mu.Unlock()
+
+	calls := make([]*QPlannedCall, len(plan.calls))
+	copy(calls, plan.calls)
+	return calls
+}
+
+func (plan *QPlan) Reset() {
+	plan.mu.Lock()
+	plan.calls = nil
+	plan.mu.Unlock()
+}
+
+func (plan *QPlan) String() string {
+	lines := make([]string, 0)
+	for _, call := range plan.Calls() {
+		lines = append(lines, call.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// While dry-run is enabled mutating calls are recorded
+// into the plan and return synthesized results
+func (svc *QService) SetDryRun(enabled bool) {
+	if !enabled {
+		svc.config.plan = nil
+		return
+	}
+
+	if svc.config.plan == nil {
+		svc.config.plan = &QPlan{}
+	}
+}
+
+func (svc *QService) Plan() *QPlan {
+	return svc.config.plan
+}
+
+func (plan *QPlan) record(call *QPlannedCall) {
+	plan.mu.Lock()
+	plan.calls = append(plan.calls, call)
+	plan.mu.Unlock()
+}
+
+func (plan *QPlan) generateId() string {
+	plan.mu.Lock()
+	defer plan.mu.Unlock()
+
+	plan.nextId++
+	return fmt.Sprintf("dry-run-%d", plan.nextId)
+}
+
+func (plan *QPlan) synthesizeTask(call *QPlannedCall, body *tasks.Task) *tasks.Task {
+	plan.record(call)
+
+	task := &tasks.Task{}
+	if body != nil {
+		task = snapshotTask(body)
+	}
+
+	task.Id = call.Task
+	if task.Id == "" {
+		task.Id = plan.generateId()
+	}
+	if call.Parent != "" {
+		task.Parent = call.Parent
+	}
+	if task.Status == "" {
+		task.Status = QNeedsActionStatus
+	}
+	task.Updated = time.Now().UTC().Format(time.RFC3339)
+
+	return task
+}
+
+func (plan *QPlan) synthesizeTasklist(call *QPlannedCall, body *tasks.TaskList) *tasks.TaskList {
+	plan.record(call)
+
+	tasklist := &tasks.TaskList{}
+	if body != nil {
+		copied := *body
+		tasklist = &copied
+	}
+
+	tasklist.Id = call.Tasklist
+	if tasklist.Id == "" {
+		tasklist.Id = plan.generateId()
+	}
+	tasklist.Updated = time.Now().UTC().Format(time.RFC3339)
+
+	return tasklist
+}
@@ -0,0 +1,124 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"time"
+)
+
+type QDuplicateOptions struct {
+	// Copies start as needsAction instead of keeping their status
+	ResetStatus bool
+
+	// Completed tasks are not copied, nor are their subtasks
+	SkipCompleted bool
+
+	// Due dates are shifted by whole days so the earliest due date
+	// lands on Anchor, the zero value keeps due dates unchanged
+	Anchor time.Time
+}
+
+// Creates a new tasklist with a copy of every task and subtask in order,
+// the new tasklist is deleted again when copying fails
+func (taskList *QTaskList) Duplicate(ctx context.Context, newTitle string, opts *QDuplicateOptions) (*QTaskList, error) {
+	if opts == nil {
+		opts = &QDuplicateOptions{}
+	}
+
+	service := taskList.service.tasks
+	if service == nil {
+		return nil, fmt.Errorf("tasq: tasklist service has no tasks service")
+	}
+
+	items, err := service.List(taskList.Id).Context(ctx).ShowHidden(true).doAll()
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := dueOffset(items, opts.Anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicate := &QTaskList{TaskList: &tasks.TaskList{Title: newTitle}}
+	created, err := taskList.service.Insert(duplicate).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	previous := ""
+	for _, item := range duplicateTasks(items, opts, offset) {
+		copied, err := item.copyTree(ctx, created.Id, "", previous, item.Children)
+		if err != nil {
+			rollbackErr := taskList.service.Delete(created.Id).Context(ctx).Do()
+			if rollbackErr != nil {
+				return nil, &QRollbackError{Err: err, RollbackErr: rollbackErr}
+			}
+			return nil, err
+		}
+
+		previous = copied.Id
+	}
+
+	return created, nil
+}
+
+func duplicateTasks(items []*QTask, opts *QDuplicateOptions, offset int) []*QTask {
+	duplicates := make([]*QTask, 0)
+	for _, item := range items {
+		if opts.SkipCompleted && item.Status == QCompletedStatus {
+			continue
+		}
+
+		task := copyTask(item.Task)
+		if opts.ResetStatus {
+			task.Status = QNeedsActionStatus
+			task.Completed = nil
+		}
+		if due, err := Time(task.Due); err == nil && offset != 0 {
+			task.Due = dueDate(due.AddDate(0, 0, offset))
+		}
+
+		duplicates = append(duplicates, &QTask{
+			Task:     task,
+			ctx:      item.ctx,
+			Children: duplicateTasks(item.Children, opts, offset),
+		})
+	}
+
+	return duplicates
+}
+
+// Days between the earliest due date and the anchor
+func dueOffset(items []*QTask, anchor time.Time) (int, error) {
+	if anchor.IsZero() {
+		return 0, nil
+	}
+
+	var earliest time.Time
+	for _, task := range flattenTasks(items) {
+		if task.Due == "" {
+			continue
+		}
+
+		due, err := Time(task.Due)
+		if err != nil {
+			return 0, err
+		}
+		if earliest.IsZero() || due.Before(earliest) {
+			earliest = due
+		}
+	}
+
+	if earliest.IsZero() {
+		return 0, nil
+	}
+
+	year, month, day := anchor.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	year, month, day = earliest.UTC().Date()
+	from := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	return int(start.Sub(from).Hours() / 24), nil
+}
@@ -0,0 +1,397 @@
+package tasq
+
+import (
+	"bufio"
+	"encoding/json"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"os"
+	"sync"
+	"time"
+)
+
+// Each entry records a mutation along with the state needed to revert it,
+// clearing completed tasks cannot be reverted so it is not journaled
+type QJournalEntry struct {
+	Time     string `json:"time"`
+	Method   string `json:"method"`
+	Tasklist string `json:"tasklist"`
+	Task     string `json:"task,omitempty"`
+
+	// Position of the task before it was moved or deleted
+	Parent   string `json:"parent,omitempty"`
+	Previous string `json:"previous,omitempty"`
+
+	// State before the mutation, Tasks holds the subtasks of a deleted
+	// task or every task of a deleted tasklist, parents before children
+	Snapshot         *tasks.Task     `json:"snapshot,omitempty"`
+	TasklistSnapshot *tasks.TaskList `json:"tasklistSnapshot,omitempty"`
+	Tasks            []*tasks.Task   `json:"tasks,omitempty"`
+}
+
+type QJournal struct {
+	mu      sync.Mutex
+	path    string
+	entries []*QJournalEntry
+}
+
+// Mutations made through the service are appended to the journal at path,
+// existing entries are loaded so Undo works across runs
+func (svc *QService) EnableJournal(path string) error {
+	journal := &QJournal{path: path}
+	if err := journal.load(); err != nil {
+		return err
+	}
+
+	svc.config.journal = journal
+	return nil
+}
+
+func (svc *QService) DisableJournal() {
+	svc.config.journal = nil
+}
+
+func (svc *QService) Journal() *QJournal {
+	return svc.config.journal
+}
+
+func (journal *QJournal) Entries() []*QJournalEntry {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+
+	entries := make([]*QJournalEntry, len(journal.entries))
+	copy(entries, journal.entries)
+	return entries
+}
+
+// Reverts the last n journaled mutations, newest first, and returns how
+// many were reverted. Mutations made while undoing are not journaled
+func (svc *QService) Undo(ctx context.Context, n int) (int, error) {
+	journal := svc.config.journal
+	if journal == nil {
+		return 0, nil
+	}
+
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+
+	config := *svc.config
+	config.journal = nil
+	undoTasks := &QTasksService{TasksService: svc.Tasks.TasksService, config: &config}
+	undoTasklists := &QTasklistsService{TasklistsService: svc.Tasklists.TasklistsService, config: &config, tasks: undoTasks}
+
+	undone := 0
+	for undone < n && len(journal.entries) > 0 {
+		last := len(journal.entries) - 1
+		entry := journal.entries[last]
+
+		remap, err := entry.undo(ctx, undoTasks, undoTasklists)
+		if err != nil {
+			if saveErr := journal.save(); saveErr != nil {
+				return undone, saveErr
+			}
+			return undone, err
+		}
+
+		journal.entries = journal.entries[:last]
+		for _, remaining := range journal.entries {
+			remaining.remap(remap)
+		}
+		undone++
+	}
+
+	return undone, journal.save()
+}
+
+func (entry *QJournalEntry) undo(ctx context.Context, taskService *QTasksService, listService *QTasklistsService) (map[string]string, error) {
+	remap := make(map[string]string)
+
+	switch entry.Method {
+	case QTasksInsertMethod:
+		return remap, taskService.Delete(entry.Tasklist, entry.Task).Context(ctx).Do()
+
+	case QTasksDeleteMethod:
+		previous := map[string]string{entry.Parent: entry.Previous}
+		restored := append([]*tasks.Task{entry.Snapshot}, entry.Tasks...)
+		return remap, restoreTasks(ctx, taskService, entry.Tasklist, restored, previous, remap)
+
+	case QTasksMoveMethod:
+		call := taskService.Move(entry.Tasklist, entry.Task).Context(ctx)
+		if entry.Parent != "" {
+			call.Parent(entry.Parent)
+		}
+		if entry.Previous != "" {
+			call.Previous(entry.Previous)
+		}
+		_, err := call.Do()
+		return remap, err
+
+	case QTasksPatchMethod, QTasksUpdateMethod:
+		snapshot := copyTask(entry.Snapshot)
+		snapshot.Id = entry.Task
+		snapshot.NullFields = emptyTaskFields(snapshot)
+		_, err := taskService.Update(entry.Tasklist, entry.Task, &QTask{Task: snapshot}).Context(ctx).Do()
+		return remap, err
+
+	case QTasklistsInsertMethod:
+		return remap, listService.Delete(entry.Tasklist).Context(ctx).Do()
+
+	case QTasklistsDeleteMethod:
+		tasklist, err := listService.Insert(&QTaskList{TaskList: &tasks.TaskList{Title: entry.TasklistSnapshot.Title}}).Context(ctx).Do()
+		if err != nil {
+			return remap, err
+		}
+		remap[entry.Tasklist] = tasklist.Id
+		return remap, restoreTasks(ctx, taskService, tasklist.Id, entry.Tasks, map[string]string{}, remap)
+
+	case QTasklistsPatchMethod, QTasklistsUpdateMethod:
+		tasklist := &tasks.TaskList{Id: entry.Tasklist, Title: entry.TasklistSnapshot.Title}
+		_, err := listService.Patch(entry.Tasklist, &QTaskList{TaskList: tasklist}).Context(ctx).Do()
+		return remap, err
+	}
+
+	return remap, nil
+}
+
+// Recreated tasks get new ids, older entries referring to them are rewritten
+func (entry *QJournalEntry) remap(remap map[string]string) {
+	replace := func(id *string) {
+		if newId, ok := remap[*id]; ok {
+			*id = newId
+		}
+	}
+
+	replace(&entry.Tasklist)
+	replace(&entry.Task)
+	replace(&entry.Parent)
+	replace(&entry.Previous)
+	if entry.Snapshot != nil {
+		replace(&entry.Snapshot.Parent)
+	}
+	for _, task := range entry.Tasks {
+		replace(&task.Parent)
+	}
+}
+
+// Inserts tasks in order, previous maps each parent to the
+// task the next inserted sibling should be placed after
+func restoreTasks(ctx context.Context, service *QTasksService, tasklistid string, items []*tasks.Task, previous map[string]string, remap map[string]string) error {
+	for _, item := range items {
+		parent := item.Parent
+		if newId, ok := remap[parent]; ok {
+			parent = newId
+		}
+
+		call := service.Insert(tasklistid, &QTask{Task: copyTask(item)}).Context(ctx)
+		if parent != "" {
+			call.Parent(parent)
+		}
+		if previous[parent] != "" {
+			call.Previous(previous[parent])
+		}
+
+		restored, err := call.Do()
+		if err != nil {
+			return err
+		}
+
+		remap[item.Id] = restored.Id
+		previous[parent] = restored.Id
+	}
+
+	return nil
+}
+
+func emptyTaskFields(task *tasks.Task) []string {
+	fields := make([]string, 0)
+	if task.Notes == "" {
+		fields = append(fields, "Notes")
+	}
+	if task.Due == "" {
+		fields = append(fields, "Due")
+	}
+	if task.Completed == nil {
+		fields = append(fields, "Completed")
+	}
+
+	return fields
+}
+
+func (journal *QJournal) append(entry *QJournalEntry) error {
+	if journal == nil || entry == nil {
+		return nil
+	}
+
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+
+	entry.Time = time.Now().UTC().Format(time.RFC3339)
+	journal.entries = append(journal.entries, entry)
+
+	file, err := os.OpenFile(journal.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(entry)
+}
+
+func (journal *QJournal) load() error {
+	file, err := os.Open(journal.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		entry := &QJournalEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return err
+		}
+		journal.entries = append(journal.entries, entry)
+	}
+
+	return scanner.Err()
+}
+
+func (journal *QJournal) save() error {
+	file, err := os.Create(journal.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range journal.entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Captures the task, its subtasks and its position before it is moved or deleted
+func (journal *QJournal) beforeTaskChange(ctx context.Context, service *QTasksService, method, tasklistid, taskid string) (*QJournalEntry, error) {
+	if journal == nil {
+		return nil, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if method == QTasksPatchMethod || method == QTasksUpdateMethod {
+		task, err := service.Get(tasklistid, taskid).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+
+		return &QJournalEntry{
+			Method:   method,
+			Tasklist: tasklistid,
+			Task:     taskid,
+			Snapshot: task.Task,
+		}, nil
+	}
+
+	items, err := service.List(tasklistid).Context(ctx).ShowHidden(true).doAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return taskChangeEntry(method, tasklistid, taskid, items), nil
+}
+
+// Builds the entry from a listing of the tasklist taken with hidden tasks,
+// nil when the task is not in it
+func taskChangeEntry(method, tasklistid, taskid string, items []*QTask) *QJournalEntry {
+	entry := &QJournalEntry{
+		Method:   method,
+		Tasklist: tasklistid,
+		Task:     taskid,
+	}
+
+	if method == QTasksPatchMethod || method == QTasksUpdateMethod {
+		for _, task := range flattenTasks(items) {
+			if task.Id == taskid {
+				entry.Snapshot = task.Task
+				return entry
+			}
+		}
+		return nil
+	}
+
+	siblings := items
+	for _, item := range items {
+		for _, child := range item.Children {
+			if child.Id == taskid {
+				siblings = item.Children
+			}
+		}
+	}
+
+	for i, sibling := range siblings {
+		if sibling.Id != taskid {
+			continue
+		}
+
+		entry.Snapshot = sibling.Task
+		entry.Parent = sibling.Parent
+		if i > 0 {
+			entry.Previous = siblings[i-1].Id
+		}
+		for _, child := range sibling.Children {
+			entry.Tasks = append(entry.Tasks, child.Task)
+		}
+	}
+
+	// Nothing to revert to when the task does not exist
+	if entry.Snapshot == nil {
+		return nil
+	}
+
+	return entry
+}
+
+func (journal *QJournal) beforeTasklistChange(ctx context.Context, lists *QTasklistsService, method, tasklistid string) (*QJournalEntry, error) {
+	if journal == nil {
+		return nil, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	entry := &QJournalEntry{
+		Method:   method,
+		Tasklist: tasklistid,
+	}
+
+	tasklist, err := lists.Get(tasklistid).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	entry.TasklistSnapshot = tasklist.TaskList
+
+	if method == QTasklistsDeleteMethod && lists.tasks != nil {
+		items, err := lists.tasks.List(tasklistid).Context(ctx).ShowHidden(true).doAll()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, task := range flattenTasks(items) {
+			entry.Tasks = append(entry.Tasks, task.Task)
+		}
+	}
+
+	return entry, nil
+}
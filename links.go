@@ -0,0 +1,67 @@
+package tasq
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	QEmailLink = "email"
+
+	// URLs written in the notes of a task
+	QNotesLink = "notes"
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+type QLink struct {
+	// Type of the link as reported by the API, e.g. QEmailLink, or QNotesLink
+	Type        string
+	Description string
+	URL         *url.URL
+}
+
+// Links attached to the task by the API followed by URLs found in the notes,
+// each URL is only returned once
+func (task *QTask) Links() []*QLink {
+	links := make([]*QLink, 0)
+	seen := make(map[string]bool)
+
+	add := func(linkType, description, rawURL string) {
+		parsed, err := url.Parse(rawURL)
+		if err != nil || seen[parsed.String()] {
+			return
+		}
+
+		seen[parsed.String()] = true
+		links = append(links, &QLink{
+			Type:        linkType,
+			Description: description,
+			URL:         parsed,
+		})
+	}
+
+	for _, link := range task.Task.Links {
+		add(link.Type, link.Description, link.Link)
+	}
+
+	for _, match := range urlPattern.FindAllString(task.Notes, -1) {
+		add(QNotesLink, "", strings.TrimRight(match, ".,;:!?)]}'"))
+	}
+
+	return links
+}
+
+// Keeps tasks with a link whose URL matches the pattern
+func ReferencesURL(pattern *regexp.Regexp) QTaskFilter {
+	return func(task *QTask) bool {
+		for _, link := range task.Links() {
+			if pattern.MatchString(link.URL.String()) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
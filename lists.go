@@ -41,6 +41,9 @@ func (taskList *QTaskList) Refresh() error {
 	entityTag := taskList.Etag
 
 	updated, err := taskList.service.Get(id).IfNoneMatch(entityTag).Do()
+	if googleapi.IsNotModified(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -82,15 +85,24 @@ func (taskLists *QTaskLists) Refresh() error {
 	entityTag := taskLists.Etag
 
 	updated, err := taskLists.service.List().IfNoneMatch(entityTag).Do()
+	if googleapi.IsNotModified(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
 	taskLists.TaskLists = updated.TaskLists
+	taskLists.Items = updated.Items
 	return nil
 }
 
-type QTasklistsService struct{ *tasks.TasklistsService }
+type QTasklistsService struct {
+	*tasks.TasklistsService
+
+	config *serviceConfig
+	tasks  *QTasksService
+}
 
 func newQTasklistsService(tokenString []byte) (*QTasklistsService, error) {
 	ctx := context.Background()
@@ -105,29 +117,54 @@ func newQTasklistsService(tokenString []byte) (*QTasklistsService, error) {
 		return &QTasklistsService{}, err
 	}
 
-	return &QTasklistsService{TasklistsService: service.Tasklists}, nil
+	return &QTasklistsService{
+		TasklistsService: service.Tasklists,
+		config:           &serviceConfig{},
+	}, nil
 }
 
 type QTasklistsDeleteCall struct {
 	*tasks.TasklistsDeleteCall
 
-	service *QTasklistsService
+	service    *QTasklistsService
+	context    context.Context
+	tasklistid string
 }
 
 func (lists *QTasklistsService) Delete(tasklistid string) *QTasklistsDeleteCall {
 	return &QTasklistsDeleteCall{
 		TasklistsDeleteCall: lists.TasklistsService.Delete(tasklistid),
 		service:             lists,
+		tasklistid:          tasklistid,
 	}
 }
 
 func (call *QTasklistsDeleteCall) Context(ctx context.Context) *QTasklistsDeleteCall {
 	call.TasklistsDeleteCall.Context(ctx)
+	call.context = ctx
 	return call
 }
 
 func (call *QTasklistsDeleteCall) Do(opts ...googleapi.CallOption) error {
-	return call.TasklistsDeleteCall.Do(opts...)
+	if plan := call.service.config.plan; plan != nil {
+		plan.record(&QPlannedCall{
+			Method:   QTasklistsDeleteMethod,
+			Tasklist: call.tasklistid,
+		})
+		return nil
+	}
+
+	journal := call.service.config.journal
+	entry, err := journal.beforeTasklistChange(call.context, call.service, QTasklistsDeleteMethod, call.tasklistid)
+	if err != nil {
+		return err
+	}
+
+	if err := call.TasklistsDeleteCall.Do(opts...); err != nil {
+		return err
+	}
+
+	return journal.append(entry)
 }
 
 func (call *QTasklistsDeleteCall) Fields(s ...googleapi.Field) *QTasklistsDeleteCall {
@@ -176,11 +213,16 @@ func (call *QTasklistsGetCall) IfNoneMatch(entityTag string) *QTasklistsGetCall
 type QTasklistsInsertCall struct {
 	*tasks.TasklistsInsertCall
 
-	service *QTasklistsService
+	service  *QTasklistsService
+	tasklist *QTaskList
 }
 
 func (lists *QTasklistsService) Insert(tasklist *QTaskList) *QTasklistsInsertCall {
-	return &QTasklistsInsertCall{TasklistsInsertCall: lists.TasklistsService.Insert(tasklist.TaskList)}
+	return &QTasklistsInsertCall{
+		TasklistsInsertCall: lists.TasklistsService.Insert(tasklist.TaskList),
+		service:             lists,
+		tasklist:            tasklist,
+	}
 }
 
 func (call *QTasklistsInsertCall) Context(ctx context.Context) *QTasklistsInsertCall {
@@ -189,7 +231,25 @@ func (call *QTasklistsInsertCall) Context(ctx context.Context) *QTasklistsInsert
 }
 
 func (call *QTasklistsInsertCall) Do(opts ...googleapi.CallOption) (*QTaskList, error) {
+	if plan := call.service.config.plan; plan != nil {
+		result := plan.synthesizeTasklist(&QPlannedCall{
+			Method: QTasklistsInsertMethod,
+			Body:   call.tasklist.TaskList,
+		}, call.tasklist.TaskList)
+		return &QTaskList{
+			TaskList: result,
+			service:  call.service,
+		}, nil
+	}
+
 	result, err := call.TasklistsInsertCall.Do(opts...)
+	if err == nil {
+		err = call.service.config.journal.append(&QJournalEntry{
+			Method:   QTasklistsInsertMethod,
+			Tasklist: result.Id,
+		})
+	}
+
 	taskList := &QTaskList{
 		TaskList: result,
 		service:  call.service,
@@ -241,6 +301,31 @@ func (call *QTasklistsListCall) Do(opts ...googleapi.CallOption) (*QTaskLists, e
 		service:   call.service}, err
 }
 
+func (call *QTasklistsListCall) doAll(opts ...googleapi.CallOption) ([]*QTaskList, error) {
+	call.TasklistsListCall.MaxResults(100)
+
+	items := make([]*QTaskList, 0)
+	for {
+		result, err := call.TasklistsListCall.Do(opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Items {
+			items = append(items, &QTaskList{
+				TaskList: item,
+				service:  call.service,
+			})
+		}
+		if result.NextPageToken == "" {
+			break
+		}
+		call.TasklistsListCall.PageToken(result.NextPageToken)
+	}
+
+	return items, nil
+}
+
 func (call *QTasklistsListCall) Fields(s ...googleapi.Field) *QTasklistsListCall {
 	call.TasklistsListCall.Fields(s...)
 	return call
@@ -264,23 +349,57 @@ func (call *QTasklistsListCall) PageToken(pageToken string) *QTasklistsListCall
 type QTasklistsPatchCall struct {
 	*tasks.TasklistsPatchCall
 
-	service *QTasklistsService
+	service    *QTasklistsService
+	context    context.Context
+	tasklistid string
+	tasklist   *QTaskList
+	ifMatch    string
 }
 
 func (lists *QTasklistsService) Patch(tasklistid string, tasklist *QTaskList) *QTasklistsPatchCall {
 	return &QTasklistsPatchCall{
 		TasklistsPatchCall: lists.TasklistsService.Patch(tasklistid, tasklist.TaskList),
 		service:            lists,
+		tasklistid:         tasklistid,
+		tasklist:           tasklist,
 	}
 }
 
 func (call *QTasklistsPatchCall) Context(ctx context.Context) *QTasklistsPatchCall {
 	call.TasklistsPatchCall.Context(ctx)
+	call.context = ctx
 	return call
 }
 
 func (call *QTasklistsPatchCall) Do(opts ...googleapi.CallOption) (*QTaskList, error) {
+	if plan := call.service.config.plan; plan != nil {
+		result := plan.synthesizeTasklist(&QPlannedCall{
+			Method:   QTasklistsPatchMethod,
+			Tasklist: call.tasklistid,
+			Body:     call.tasklist.TaskList,
+		}, call.tasklist.TaskList)
+		return &QTaskList{
+			TaskList: result,
+			service:  call.service,
+		}, nil
+	}
+
+	journal := call.service.config.journal
+	entry, err := journal.beforeTasklistChange(call.context, call.service, QTasklistsPatchMethod, call.tasklistid)
+	if err != nil {
+		return &QTaskList{}, err
+	}
+
+	setIfMatch(call.Header(), call.service.config, call.ifMatch, call.tasklist.Etag)
+
 	result, err := call.TasklistsPatchCall.Do(opts...)
+	if isPreconditionFailed(err) {
+		return &QTaskList{}, call.service.conflict(call.context, call.tasklistid, call.tasklist, err)
+	}
+	if err == nil {
+		err = journal.append(entry)
+	}
+
 	taskList := &QTaskList{
 		TaskList: result,
 		service:  call.service,
@@ -294,23 +413,65 @@ func (call *QTasklistsPatchCall) Fields(s ...googleapi.Field) *QTasklistsPatchCa
 	return call
 }
 
+func (call *QTasklistsPatchCall) IfMatch(entityTag string) *QTasklistsPatchCall {
+	call.ifMatch = entityTag
+	return call
+}
+
 type QTasklistsUpdateCall struct {
 	*tasks.TasklistsUpdateCall
 
-	service *QTasklistsService
+	service    *QTasklistsService
+	context    context.Context
+	tasklistid string
+	tasklist   *QTaskList
+	ifMatch    string
 }
 
 func (lists *QTasklistsService) Update(taskslistid string, tasklist *QTaskList) *QTasklistsUpdateCall {
-	return &QTasklistsUpdateCall{TasklistsUpdateCall: lists.TasklistsService.Update(taskslistid, tasklist.TaskList)}
+	return &QTasklistsUpdateCall{
+		TasklistsUpdateCall: lists.TasklistsService.Update(taskslistid, tasklist.TaskList),
+		service:             lists,
+		tasklistid:          taskslistid,
+		tasklist:            tasklist,
+	}
 }
 
 func (call *QTasklistsUpdateCall) Context(ctx context.Context) *QTasklistsUpdateCall {
 	call.TasklistsUpdateCall.Context(ctx)
+	call.context = ctx
 	return call
 }
 
 func (call *QTasklistsUpdateCall) Do(opts ...googleapi.CallOption) (*QTaskList, error) {
+	if plan := call.service.config.plan; plan != nil {
+		result := plan.synthesizeTasklist(&QPlannedCall{
+			Method:   QTasklistsUpdateMethod,
+			Tasklist: call.tasklistid,
+			Body:     call.tasklist.TaskList,
+		}, call.tasklist.TaskList)
+		return &QTaskList{
+			TaskList: result,
+			service:  call.service,
+		}, nil
+	}
+
+	journal := call.service.config.journal
+	entry, err := journal.beforeTasklistChange(call.context, call.service, QTasklistsUpdateMethod, call.tasklistid)
+	if err != nil {
+		return &QTaskList{}, err
+	}
+
+	setIfMatch(call.Header(), call.service.config, call.ifMatch, call.tasklist.Etag)
+
 	result, err := call.TasklistsUpdateCall.Do(opts...)
+	if isPreconditionFailed(err) {
+		return &QTaskList{}, call.service.conflict(call.context, call.tasklistid, call.tasklist, err)
+	}
+	if err == nil {
+		err = journal.append(entry)
+	}
+
 	taskList := &QTaskList{
 		TaskList: result,
 		service:  call.service,
@@ -323,3 +484,8 @@ func (call *QTasklistsUpdateCall) Fields(s ...googleapi.Field) *QTasklistsUpdate
 	call.TasklistsUpdateCall.Fields(s...)
 	return call
 }
+
+func (call *QTasklistsUpdateCall) IfMatch(entityTag string) *QTasklistsUpdateCall {
+	call.ifMatch = entityTag
+	return call
+}
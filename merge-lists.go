@@ -0,0 +1,147 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"strings"
+)
+
+const (
+	QAppendPlacement     = "placement.append"
+	QInterleavePlacement = "placement.interleave"
+)
+
+type QMergeListsOptions struct {
+	// QAppendPlacement adds the moved tasks after the destination tasks,
+	// QInterleavePlacement places each one by due date among them,
+	// tasks without a due date are appended in both cases
+	Placement string
+
+	// Source tasks whose normalized title is already in the destination,
+	// or earlier in the source, are deleted instead of moved, their
+	// subtasks are moved under the task with the same title
+	Dedupe bool
+
+	// Delete the source tasklist once every task has been moved
+	DeleteSource bool
+}
+
+// Moves every top-level task of the source tasklist along with its
+// subtasks into the destination tasklist, returns the moved tasks
+func (svc *QService) MergeLists(ctx context.Context, srcID, dstID string, opts *QMergeListsOptions) ([]*QTask, error) {
+	if opts == nil {
+		opts = &QMergeListsOptions{}
+	}
+	if srcID == dstID {
+		return nil, fmt.Errorf("tasq: cannot merge tasklist %q into itself", srcID)
+	}
+
+	source, err := svc.Tasks.List(srcID).Context(ctx).ShowHidden(true).ShowCompleted(true).doAll()
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := svc.Tasks.List(dstID).Context(ctx).ShowHidden(true).ShowCompleted(true).doAll()
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[string]*QTask)
+	for _, task := range merged {
+		if _, ok := titles[normalizeTitle(task.Title)]; !ok {
+			titles[normalizeTitle(task.Title)] = task
+		}
+	}
+
+	moved := make([]*QTask, 0)
+	for _, task := range source {
+		title := normalizeTitle(task.Title)
+		if match, ok := titles[title]; opts.Dedupe && ok {
+			if err := match.appendSubtasks(ctx, dstID, task.Children); err != nil {
+				return moved, err
+			}
+			if err := svc.Tasks.Delete(srcID, task.Id).Context(ctx).Do(); err != nil {
+				return moved, err
+			}
+			continue
+		}
+
+		i := len(merged)
+		if opts.Placement == QInterleavePlacement {
+			i = dueInsertIndex(merged, task)
+		}
+
+		previous := ""
+		if i > 0 {
+			previous = merged[i-1].Id
+		}
+
+		// The source was listed in full, so its children are complete
+		copied, err := task.moveToListAfter(ctx, dstID, previous, task.Children)
+		if err != nil {
+			return moved, err
+		}
+		titles[title] = copied
+
+		merged = append(merged[:i], append([]*QTask{copied}, merged[i:]...)...)
+		moved = append(moved, copied)
+	}
+
+	if opts.DeleteSource {
+		if err := svc.Tasklists.Delete(srcID).Context(ctx).Do(); err != nil {
+			return moved, err
+		}
+	}
+
+	return moved, nil
+}
+
+// Copies subtasks after the existing subtasks of the task
+func (task *QTask) appendSubtasks(ctx context.Context, tasklistid string, children []*QTask) error {
+	previous := ""
+	if len(task.Children) > 0 {
+		previous = task.Children[len(task.Children)-1].Id
+	}
+
+	for _, child := range children {
+		call := task.ctx.service.Insert(tasklistid, &QTask{Task: copyTask(child.Task)}).Context(ctx).Parent(task.Id)
+		if previous != "" {
+			call.Previous(previous)
+		}
+
+		copied, err := call.Do()
+		if err != nil {
+			return err
+		}
+
+		previous = copied.Id
+		task.Children = append(task.Children, copied)
+	}
+
+	return nil
+}
+
+// Index right after the last task due no later than the given task
+func dueInsertIndex(merged []*QTask, task *QTask) int {
+	due, err := Time(task.Due)
+	if err != nil {
+		return len(merged)
+	}
+
+	index := 0
+	for i, other := range merged {
+		otherDue, err := Time(other.Due)
+		if err != nil {
+			continue
+		}
+		if !otherDue.After(due) {
+			index = i + 1
+		}
+	}
+
+	return index
+}
+
+func normalizeTitle(title string) string {
+	return strings.Join(tokenize(title), " ")
+}
@@ -0,0 +1,220 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"strings"
+)
+
+const QMaxMergeAttempts = 3
+
+type QFieldConflict struct {
+	Field  string
+	Base   string
+	Local  string
+	Remote string
+}
+
+type QMergeConflictError struct {
+	Local     *QTask
+	Remote    *QTask
+	Conflicts []QFieldConflict
+
+	taskid string
+}
+
+func (e *QMergeConflictError) Error() string {
+	fields := make([]string, 0)
+	for _, conflict := range e.Conflicts {
+		fields = append(fields, conflict.Field)
+	}
+
+	return fmt.Sprintf("tasq: task %s has conflicting remote edits to %s", e.taskid, strings.Join(fields, ", "))
+}
+
+type mergeField struct {
+	name  string
+	get   func(task *tasks.Task) string
+	apply func(dst, src *tasks.Task)
+}
+
+var mergeFields = []mergeField{
+	{
+		name:  "title",
+		get:   func(task *tasks.Task) string { return task.Title },
+		apply: func(dst, src *tasks.Task) { dst.Title = src.Title },
+	},
+	{
+		name:  "notes",
+		get:   func(task *tasks.Task) string { return task.Notes },
+		apply: func(dst, src *tasks.Task) { dst.Notes = src.Notes },
+	},
+	{
+		name: "status",
+		get:  func(task *tasks.Task) string { return task.Status },
+		apply: func(dst, src *tasks.Task) {
+			dst.Status = src.Status
+			dst.Completed = src.Completed
+		},
+	},
+	{
+		name:  "due",
+		get:   func(task *tasks.Task) string { return task.Due },
+		apply: func(dst, src *tasks.Task) { dst.Due = src.Due },
+	},
+	{
+		name:  "parent",
+		get:   func(task *tasks.Task) string { return task.Parent },
+		apply: func(dst, src *tasks.Task) { dst.Parent = src.Parent },
+	},
+	{
+		name:  "position",
+		get:   func(task *tasks.Task) string { return task.Position },
+		apply: func(dst, src *tasks.Task) { dst.Position = src.Position },
+	},
+}
+
+// When enabled, a conflicting Update or Patch of a task is retried
+// after merging the local edits into the remote version, as long
+// as both sides did not change the same fields
+func (svc *QService) SetAutoMerge(enabled bool) {
+	svc.config.merge = enabled
+}
+
+func (service *QTasksService) resolveConflict(ctx context.Context, tasklistid, taskid string, local *QTask, err error) (*QTask, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !service.config.merge || local.base == nil {
+		return &QTask{}, service.conflict(ctx, tasklistid, taskid, local, err)
+	}
+
+	for attempt := 0; attempt < QMaxMergeAttempts; attempt++ {
+		remote, getErr := service.Get(tasklistid, taskid).Context(ctx).Do()
+		if getErr != nil {
+			return &QTask{}, &QConflictError{Err: err, Local: local, taskid: taskid}
+		}
+
+		merged, conflicts := mergeTasks(local.base, local.Task, remote.Task)
+		if len(conflicts) > 0 {
+			return &QTask{}, &QMergeConflictError{
+				Local:     local,
+				Remote:    remote,
+				Conflicts: conflicts,
+				taskid:    taskid,
+			}
+		}
+
+		call := service.TasksService.Update(tasklistid, taskid, merged).Context(ctx)
+		call.Header().Set("If-Match", remote.Etag)
+
+		var result *tasks.Task
+		result, err = call.Do()
+		if isPreconditionFailed(err) {
+			continue
+		}
+		if err != nil {
+			return &QTask{}, err
+		}
+
+		updated := &QTask{
+			Task: result,
+			ctx: &QTaskCallContext{
+				service:    service,
+				tasklistid: tasklistid,
+			},
+			base: snapshotTask(result),
+		}
+		if merged.Parent != remote.Parent || merged.Position != remote.Position {
+			return updated.moveTo(ctx, merged.Parent, merged.Position)
+		}
+
+		return updated, nil
+	}
+
+	return &QTask{}, service.conflict(ctx, tasklistid, taskid, local, err)
+}
+
+// Field-level three-way merge, local edits are applied on top of remote
+func mergeTasks(base, local, remote *tasks.Task) (*tasks.Task, []QFieldConflict) {
+	merged := *remote
+	conflicts := make([]QFieldConflict, 0)
+
+	for _, field := range mergeFields {
+		baseValue := field.get(base)
+		localValue := field.get(local)
+		remoteValue := field.get(remote)
+
+		switch {
+		case localValue == baseValue, localValue == remoteValue:
+			continue
+		case remoteValue == baseValue:
+			field.apply(&merged, local)
+		default:
+			conflicts = append(conflicts, QFieldConflict{
+				Field:  field.name,
+				Base:   baseValue,
+				Local:  localValue,
+				Remote: remoteValue,
+			})
+		}
+	}
+
+	return &merged, conflicts
+}
+
+// Moves the task under parent, after the sibling closest before position
+func (task *QTask) moveTo(ctx context.Context, parent, position string) (*QTask, error) {
+	items, err := task.ctx.service.List(task.ctx.tasklistid).Context(ctx).ShowHidden(true).doAll()
+	if err != nil {
+		return task, err
+	}
+
+	siblings := items
+	if parent != "" {
+		siblings = nil
+		for _, item := range items {
+			if item.Id == parent {
+				siblings = item.Children
+			}
+		}
+	}
+
+	previous := ""
+	previousPosition := ""
+	for _, sibling := range siblings {
+		if sibling.Id == task.Id {
+			continue
+		}
+		if sibling.Position < position && sibling.Position > previousPosition {
+			previous = sibling.Id
+			previousPosition = sibling.Position
+		}
+	}
+
+	call := task.ctx.service.Move(task.ctx.tasklistid, task.Id).Context(ctx)
+	if parent != "" {
+		call.Parent(parent)
+	}
+	if previous != "" {
+		call.Previous(previous)
+	}
+
+	return call.Do()
+}
+
+func snapshotTask(task *tasks.Task) *tasks.Task {
+	if task == nil {
+		return nil
+	}
+
+	snapshot := *task
+	if task.Completed != nil {
+		completed := *task.Completed
+		snapshot.Completed = &completed
+	}
+
+	return &snapshot
+}
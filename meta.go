@@ -0,0 +1,216 @@
+package tasq
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	metaOpenFence  = "```meta"
+	metaCloseFence = "```"
+)
+
+// Key/value fields kept in a fenced footer at the end of the notes, e.g.
+//
+//	```meta
+//	assignee: alice
+//	estimate: 2h
+//	```
+type QMeta map[string]string
+
+func (meta QMeta) String(key string) string {
+	return meta[normalizeMetaKey(key)]
+}
+
+func (meta QMeta) Int(key string) (int, bool) {
+	value, err := strconv.Atoi(meta.String(key))
+	return value, err == nil
+}
+
+func (meta QMeta) Float(key string) (float64, bool) {
+	value, err := strconv.ParseFloat(meta.String(key), 64)
+	return value, err == nil
+}
+
+func (meta QMeta) Bool(key string) (bool, bool) {
+	value, err := strconv.ParseBool(meta.String(key))
+	return value, err == nil
+}
+
+func (meta QMeta) Duration(key string) (time.Duration, bool) {
+	value, err := time.ParseDuration(meta.String(key))
+	return value, err == nil
+}
+
+func (meta QMeta) Time(key string) (time.Time, bool) {
+	value, err := Time(meta.String(key))
+	return value, err == nil
+}
+
+// Returns an empty map when the notes have no footer
+func (task *QTask) Meta() (QMeta, error) {
+	footer, err := splitMeta(task.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMeta(footer.lines)
+}
+
+// Notes without the footer, notes with a malformed footer are returned as is
+func (task *QTask) PlainNotes() string {
+	footer, err := splitMeta(task.Notes)
+	if err != nil {
+		return task.Notes
+	}
+
+	return joinMeta(footer, QMeta{})
+}
+
+// Sets a field in the footer leaving the rest of the notes untouched,
+// a nil or empty value removes the field. Times are written as RFC3339
+func (task *QTask) SetMeta(key string, value interface{}) error {
+	key = normalizeMetaKey(key)
+	if key == "" || strings.ContainsAny(key, ":\n") {
+		return fmt.Errorf("tasq: invalid meta key %q", key)
+	}
+
+	footer, err := splitMeta(task.Notes)
+	if err != nil {
+		return err
+	}
+
+	meta, err := parseMeta(footer.lines)
+	if err != nil {
+		return err
+	}
+
+	formatted := formatMetaValue(value)
+	if strings.Contains(formatted, "\n") {
+		return fmt.Errorf("tasq: meta value for %q spans multiple lines", key)
+	}
+	if formatted == "" {
+		delete(meta, key)
+	} else {
+		meta[key] = formatted
+	}
+
+	task.Notes = joinMeta(footer, meta)
+	return nil
+}
+
+// Notes split around the footer, before and after hold the surrounding
+// text byte for byte, including the line breaks next to the fences
+type metaFooter struct {
+	before string
+	lines  []string
+	after  string
+	found  bool
+}
+
+// Splits notes around the last footer, lines holds the lines between the fences
+func splitMeta(notes string) (*metaFooter, error) {
+	lines := strings.Split(notes, "\n")
+
+	open := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == metaOpenFence {
+			open = i
+		}
+	}
+	if open == -1 {
+		return &metaFooter{before: notes}, nil
+	}
+
+	for i := open + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != metaCloseFence {
+			continue
+		}
+
+		footer := &metaFooter{lines: lines[open+1 : i], found: true}
+		if open > 0 {
+			footer.before = strings.Join(lines[:open], "\n") + "\n"
+		}
+		if i+1 < len(lines) {
+			footer.after = "\n" + strings.Join(lines[i+1:], "\n")
+		}
+		return footer, nil
+	}
+
+	return nil, fmt.Errorf("tasq: meta footer is not closed")
+}
+
+func parseMeta(block []string) (QMeta, error) {
+	meta := make(QMeta)
+	for _, line := range block {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		pair := strings.SplitN(line, ":", 2)
+		if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" {
+			return nil, fmt.Errorf("tasq: malformed meta line %q", line)
+		}
+
+		meta[normalizeMetaKey(pair[0])] = strings.TrimSpace(pair[1])
+	}
+
+	return meta, nil
+}
+
+// The footer owns the line break separating it from the text before it,
+// or from the text after it when it starts the notes, so adding and
+// then removing every field gives back the original notes
+func joinMeta(footer *metaFooter, meta QMeta) string {
+	if len(meta) == 0 {
+		switch {
+		case !footer.found:
+			return footer.before
+		case footer.before != "":
+			return strings.TrimSuffix(footer.before, "\n") + footer.after
+		}
+		return strings.TrimPrefix(footer.after, "\n")
+	}
+
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := []string{metaOpenFence}
+	for _, key := range keys {
+		lines = append(lines, key+": "+meta[key])
+	}
+	lines = append(lines, metaCloseFence)
+	block := strings.Join(lines, "\n")
+
+	if footer.found {
+		return footer.before + block + footer.after
+	}
+	if footer.before == "" {
+		return block
+	}
+
+	return footer.before + "\n" + block
+}
+
+func formatMetaValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case string:
+		return strings.TrimSpace(value)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func normalizeMetaKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
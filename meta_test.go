@@ -0,0 +1,216 @@
+package tasq
+
+import (
+	"google.golang.org/api/tasks/v1"
+	"reflect"
+	"testing"
+)
+
+func TestSplitMeta(t *testing.T) {
+	cases := []struct {
+		name   string
+		notes  string
+		footer *metaFooter
+		err    bool
+	}{
+		{
+			name:   "empty notes",
+			notes:  "",
+			footer: &metaFooter{before: ""},
+		},
+		{
+			name:   "no footer",
+			notes:  "buy milk\n",
+			footer: &metaFooter{before: "buy milk\n"},
+		},
+		{
+			name:   "footer only",
+			notes:  "```meta\na: 1\n```",
+			footer: &metaFooter{lines: []string{"a: 1"}, found: true},
+		},
+		{
+			name:   "text before footer",
+			notes:  "buy milk  \n\n```meta\na: 1\n```",
+			footer: &metaFooter{before: "buy milk  \n\n", lines: []string{"a: 1"}, found: true},
+		},
+		{
+			name:   "text after footer",
+			notes:  "buy milk\n```meta\na: 1\n```\nmore",
+			footer: &metaFooter{before: "buy milk\n", lines: []string{"a: 1"}, after: "\nmore", found: true},
+		},
+		{
+			name:  "unclosed fence",
+			notes: "buy milk\n```meta\na: 1",
+			err:   true,
+		},
+	}
+
+	for _, c := range cases {
+		footer, err := splitMeta(c.notes)
+		if (err != nil) != c.err {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(footer, c.footer) {
+			t.Errorf("%s: got %+v, want %+v", c.name, footer, c.footer)
+		}
+	}
+}
+
+func TestParseMeta(t *testing.T) {
+	cases := []struct {
+		name  string
+		lines []string
+		meta  QMeta
+		err   bool
+	}{
+		{
+			name:  "empty block",
+			lines: []string{},
+			meta:  QMeta{},
+		},
+		{
+			name:  "fields",
+			lines: []string{"Assignee: alice", "", "url: https://example.com"},
+			meta:  QMeta{"assignee": "alice", "url": "https://example.com"},
+		},
+		{
+			name:  "line without colon",
+			lines: []string{"assignee alice"},
+			err:   true,
+		},
+		{
+			name:  "empty key",
+			lines: []string{": alice"},
+			err:   true,
+		},
+	}
+
+	for _, c := range cases {
+		meta, err := parseMeta(c.lines)
+		if (err != nil) != c.err {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if !c.err && !reflect.DeepEqual(meta, c.meta) {
+			t.Errorf("%s: got %v, want %v", c.name, meta, c.meta)
+		}
+	}
+}
+
+func TestJoinMeta(t *testing.T) {
+	cases := []struct {
+		name   string
+		footer *metaFooter
+		meta   QMeta
+		notes  string
+	}{
+		{
+			name:   "add to empty notes",
+			footer: &metaFooter{},
+			meta:   QMeta{"b": "2", "a": "1"},
+			notes:  "```meta\na: 1\nb: 2\n```",
+		},
+		{
+			name:   "add after text",
+			footer: &metaFooter{before: "buy milk"},
+			meta:   QMeta{"a": "1"},
+			notes:  "buy milk\n```meta\na: 1\n```",
+		},
+		{
+			name:   "rewrite keeps surrounding text",
+			footer: &metaFooter{before: "buy milk  \n\n", after: "\nmore", found: true},
+			meta:   QMeta{"a": "2"},
+			notes:  "buy milk  \n\n```meta\na: 2\n```\nmore",
+		},
+		{
+			name:   "remove last field",
+			footer: &metaFooter{before: "buy milk\n", after: "\nmore", found: true},
+			meta:   QMeta{},
+			notes:  "buy milk\nmore",
+		},
+		{
+			name:   "remove footer starting the notes",
+			footer: &metaFooter{after: "\nmore", found: true},
+			meta:   QMeta{},
+			notes:  "more",
+		},
+		{
+			name:   "nothing to remove",
+			footer: &metaFooter{before: "buy milk\n"},
+			meta:   QMeta{},
+			notes:  "buy milk\n",
+		},
+	}
+
+	for _, c := range cases {
+		if notes := joinMeta(c.footer, c.meta); notes != c.notes {
+			t.Errorf("%s: got %q, want %q", c.name, notes, c.notes)
+		}
+	}
+}
+
+func TestSetMetaRoundTrip(t *testing.T) {
+	for _, notes := range []string{"", "buy milk", "buy milk\n", "hello  \n\n", "line one\n\nline two"} {
+		task := &QTask{Task: &tasks.Task{Notes: notes}}
+
+		if err := task.SetMeta("estimate", "2h"); err != nil {
+			t.Fatalf("%q: %v", notes, err)
+		}
+		meta, err := task.Meta()
+		if err != nil || meta.String("estimate") != "2h" {
+			t.Errorf("%q: got %v, %v after set", notes, meta, err)
+		}
+
+		if err := task.SetMeta("estimate", nil); err != nil {
+			t.Fatalf("%q: %v", notes, err)
+		}
+		if task.Notes != notes {
+			t.Errorf("%q: got %q after removing the last field", notes, task.Notes)
+		}
+	}
+}
+
+func TestPlainNotes(t *testing.T) {
+	cases := []struct {
+		notes string
+		plain string
+	}{
+		{"", ""},
+		{"buy milk", "buy milk"},
+		{"buy milk\n```meta\nrrule: FREQ=DAILY\n```", "buy milk"},
+		{"```meta\na: 1\n```\nmore", "more"},
+		{"buy milk\n```meta\na: 1", "buy milk\n```meta\na: 1"},
+	}
+
+	for _, c := range cases {
+		task := &QTask{Task: &tasks.Task{Notes: c.notes}}
+		if plain := task.PlainNotes(); plain != c.plain {
+			t.Errorf("%q: got %q, want %q", c.notes, plain, c.plain)
+		}
+	}
+}
+
+func TestSetMetaErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		notes string
+		key   string
+		value interface{}
+	}{
+		{name: "unclosed fence", notes: "```meta\na: 1", key: "b", value: 1},
+		{name: "malformed line", notes: "```meta\nnot a field\n```", key: "b", value: 1},
+		{name: "invalid key", key: "a:b", value: 1},
+		{name: "multi-line value", key: "a", value: "one\ntwo"},
+	}
+
+	for _, c := range cases {
+		task := &QTask{Task: &tasks.Task{Notes: c.notes}}
+		if err := task.SetMeta(c.key, c.value); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+		if task.Notes != c.notes {
+			t.Errorf("%s: notes changed to %q", c.name, task.Notes)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package tasq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calendar date without a time of day or location
+type QDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func DateOf(t time.Time) QDate {
+	year, month, day := t.Date()
+	return QDate{Year: year, Month: month, Day: day}
+}
+
+// Midnight of the date in the given location
+func (date QDate) In(loc *time.Location) time.Time {
+	return time.Date(date.Year, date.Month, date.Day, 0, 0, 0, 0, loc)
+}
+
+func (date QDate) AddDays(days int) QDate {
+	return DateOf(date.In(time.UTC).AddDate(0, 0, days))
+}
+
+// Clamps to the last day of the month, so Jan 31 plus a month is Feb 28
+func (date QDate) AddMonths(months int) QDate {
+	first := time.Date(date.Year, date.Month+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); date.Day > last {
+		return QDate{Year: first.Year(), Month: first.Month(), Day: last}
+	}
+
+	return QDate{Year: first.Year(), Month: first.Month(), Day: date.Day}
+}
+
+func (date QDate) Weekday() time.Weekday {
+	return date.In(time.UTC).Weekday()
+}
+
+func (date QDate) String() string {
+	return date.In(time.UTC).Format("2006-01-02")
+}
+
+type QDateUnit int
+
+const (
+	QDays QDateUnit = iota + 1
+	QWeeks
+	QMonths
+	QYears
+)
+
+// Words of a language, phrases are matched after lower casing
+// and removing accents, so entries should be written that way
+type QDateLocale struct {
+	Today     []string
+	Tomorrow  []string
+	Yesterday []string
+
+	// "next fri", "next week"
+	Next []string
+
+	// "in 3 days", "in a week"
+	In []string
+
+	// "end of month"
+	EndOf []string
+
+	Weekdays map[string]time.Weekday
+	Units    map[string]QDateUnit
+	Numbers  map[string]int
+}
+
+var QEnglish = &QDateLocale{
+	Today:     []string{"today", "tod"},
+	Tomorrow:  []string{"tomorrow", "tmr", "tmrw"},
+	Yesterday: []string{"yesterday"},
+	Next:      []string{"next"},
+	In:        []string{"in"},
+	EndOf:     []string{"end of", "end of the"},
+	Weekdays: map[string]time.Weekday{
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+		"sunday": time.Sunday, "sun": time.Sunday,
+	},
+	Units: map[string]QDateUnit{
+		"day": QDays, "days": QDays,
+		"week": QWeeks, "weeks": QWeeks,
+		"month": QMonths, "months": QMonths,
+		"year": QYears, "years": QYears,
+	},
+	Numbers: map[string]int{
+		"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+		"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	},
+}
+
+type QDateParser struct {
+	// Defaults to time.Now
+	Now func() time.Time
+
+	// Location in which today is determined, defaults to time.Local
+	Location *time.Location
+
+	// Tried in order, defaults to English
+	Locales []*QDateLocale
+}
+
+// Used by SetDueFromString and the task builder
+var DefaultDateParser = &QDateParser{}
+
+// Parses phrases such as "tomorrow", "fri", "next fri", "in 3 days",
+// "next month" or "end of month", as well as dates like 2026-10-19.
+// A weekday on its own is the next one after today, "next" picks it
+// from next week. Weeks start on Monday and end on Sunday
+func (parser *QDateParser) Parse(phrase string) (QDate, error) {
+	phrase = strings.Join(strings.Fields(foldText(phrase)), " ")
+	today := parser.today()
+
+	if date, err := time.Parse("2006-01-02", phrase); err == nil {
+		return DateOf(date), nil
+	}
+
+	locales := parser.Locales
+	if len(locales) == 0 {
+		locales = []*QDateLocale{QEnglish}
+	}
+
+	for _, locale := range locales {
+		if date, ok := locale.parse(phrase, today); ok {
+			return date, nil
+		}
+	}
+
+	return QDate{}, fmt.Errorf("tasq: cannot parse date %q", phrase)
+}
+
+func (parser *QDateParser) today() QDate {
+	now := time.Now
+	if parser.Now != nil {
+		now = parser.Now
+	}
+
+	loc := time.Local
+	if parser.Location != nil {
+		loc = parser.Location
+	}
+
+	return DateOf(now().In(loc))
+}
+
+func (locale *QDateLocale) parse(phrase string, today QDate) (QDate, bool) {
+	switch {
+	case contains(locale.Today, phrase):
+		return today, true
+	case contains(locale.Tomorrow, phrase):
+		return today.AddDays(1), true
+	case contains(locale.Yesterday, phrase):
+		return today.AddDays(-1), true
+	}
+
+	if weekday, ok := locale.Weekdays[phrase]; ok {
+		return today.AddDays(daysUntil(today.Weekday(), weekday)), true
+	}
+
+	if rest, ok := trimWord(locale.Next, phrase); ok {
+		if weekday, ok := locale.Weekdays[rest]; ok {
+			return startOfWeek(today).AddDays(7 + daysSinceMonday(weekday)), true
+		}
+		if unit, ok := locale.Units[rest]; ok {
+			return addUnits(today, unit, 1), true
+		}
+	}
+
+	if rest, ok := trimWord(locale.In, phrase); ok {
+		fields := strings.Fields(rest)
+		if len(fields) == 2 {
+			n, err := strconv.Atoi(fields[0])
+			if word, ok := locale.Numbers[fields[0]]; ok {
+				n, err = word, nil
+			}
+			// Counts below one would give dates in the past or today
+			if unit, ok := locale.Units[fields[1]]; ok && err == nil && n > 0 {
+				return addUnits(today, unit, n), true
+			}
+		}
+	}
+
+	if rest, ok := trimWord(locale.EndOf, phrase); ok {
+		switch locale.Units[rest] {
+		case QWeeks:
+			return startOfWeek(today).AddDays(6), true
+		case QMonths:
+			return QDate{Year: today.Year, Month: today.Month, Day: 1}.AddMonths(1).AddDays(-1), true
+		case QYears:
+			return QDate{Year: today.Year, Month: time.December, Day: 31}, true
+		}
+	}
+
+	return QDate{}, false
+}
+
+func (task *QTask) SetDueFromString(phrase string) error {
+	date, err := DefaultDateParser.Parse(phrase)
+	if err != nil {
+		return err
+	}
+
+	task.Due = dueDate(date.In(time.UTC))
+	return nil
+}
+
+func addUnits(date QDate, unit QDateUnit, n int) QDate {
+	switch unit {
+	case QWeeks:
+		return date.AddDays(7 * n)
+	case QMonths:
+		return date.AddMonths(n)
+	case QYears:
+		return date.AddMonths(12 * n)
+	}
+
+	return date.AddDays(n)
+}
+
+// Between 1 and 7 days ahead
+func daysUntil(from, to time.Weekday) int {
+	days := (int(to) - int(from) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+
+	return days
+}
+
+func daysSinceMonday(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func startOfWeek(date QDate) QDate {
+	return date.AddDays(-daysSinceMonday(date.Weekday()))
+}
+
+// Strips the longest matching leading word or words
+func trimWord(words []string, phrase string) (string, bool) {
+	rest, found := "", false
+	for _, word := range words {
+		if strings.HasPrefix(phrase, word+" ") {
+			trimmed := strings.TrimPrefix(phrase, word+" ")
+			if !found || len(trimmed) < len(rest) {
+				rest, found = trimmed, true
+			}
+		}
+	}
+
+	return rest, found
+}
+
+func contains(words []string, phrase string) bool {
+	for _, word := range words {
+		if word == phrase {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package tasq
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateParserParse(t *testing.T) {
+	// A Wednesday
+	parser := &QDateParser{
+		Now:      func() time.Time { return time.Date(2024, time.January, 31, 15, 0, 0, 0, time.UTC) },
+		Location: time.UTC,
+	}
+
+	cases := []struct {
+		phrase string
+		date   string
+	}{
+		{"today", "2024-01-31"},
+		{"Tomorrow", "2024-02-01"},
+		{"  TMRW ", "2024-02-01"},
+		{"yesterday", "2024-01-30"},
+
+		{"fri", "2024-02-02"},
+		{"Monday", "2024-02-05"},
+		{"wed", "2024-02-07"},
+
+		{"next mon", "2024-02-05"},
+		{"next fri", "2024-02-09"},
+		{"next  sunday", "2024-02-11"},
+		{"next week", "2024-02-07"},
+		{"next month", "2024-02-29"},
+		{"next year", "2025-01-31"},
+
+		{"in 3 days", "2024-02-03"},
+		{"in a week", "2024-02-07"},
+		{"in two months", "2024-03-31"},
+		{"in 1 year", "2025-01-31"},
+
+		{"end of week", "2024-02-04"},
+		{"end of the month", "2024-01-31"},
+		{"end of year", "2024-12-31"},
+
+		{"2024-03-05", "2024-03-05"},
+	}
+
+	for _, c := range cases {
+		date, err := parser.Parse(c.phrase)
+		if err != nil {
+			t.Errorf("%q: %v", c.phrase, err)
+			continue
+		}
+		if date.String() != c.date {
+			t.Errorf("%q: got %s, want %s", c.phrase, date, c.date)
+		}
+	}
+}
+
+func TestDateParserParseInvalid(t *testing.T) {
+	parser := &QDateParser{
+		Now:      func() time.Time { return time.Date(2024, time.January, 31, 15, 0, 0, 0, time.UTC) },
+		Location: time.UTC,
+	}
+
+	for _, phrase := range []string{
+		"",
+		"someday",
+		"next",
+		"next funday",
+		"in three",
+		"in 3 fortnights",
+		"in 0 days",
+		"in -3 days",
+		"end of day",
+		"2024-02-30",
+		"31/01/2024",
+	} {
+		if date, err := parser.Parse(phrase); err == nil {
+			t.Errorf("%q: got %s, want an error", phrase, date)
+		}
+	}
+}
+
+func TestDateParserLocation(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	parser := &QDateParser{
+		Now:      func() time.Time { return time.Date(2024, time.January, 31, 23, 30, 0, 0, time.UTC) },
+		Location: tokyo,
+	}
+
+	date, err := parser.Parse("today")
+	if err != nil || date.String() != "2024-02-01" {
+		t.Errorf("got %s, %v, want 2024-02-01", date, err)
+	}
+}
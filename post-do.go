@@ -8,10 +8,12 @@ const (
 	QCompletedFilter   = "filter.completed"
 	QNeedsActionFilter = "filter.needs_action"
 	QOverdueFilter     = "filter.overdue"
+	QDeletedFilter     = "filter.deleted"
 
 	QPositionSort    = "sort.position"
 	QLatestFirstSort = "sort.latest_first"
 	QOldestFirstSort = "sort.oldest_first"
+	QPrioritySort    = "sort.priority"
 )
 
 func raiseTasks(list []*QTask) []*QTask {
@@ -23,25 +25,104 @@ func raiseTasks(list []*QTask) []*QTask {
 		if task.Parent == "" {
 			topLevelTasks[task.Id] = len(raisedTasks)
 			raisedTasks = append(raisedTasks, task)
-		} else {
-			parentIdx := topLevelTasks[task.Parent]
-			raisedTasks[parentIdx].Children = append(raisedTasks[parentIdx].Children, task)
 		}
 	}
 
+	// Children whose parent was filtered out are raised to the top level
+	for _, task := range list {
+		if task.Parent == "" {
+			continue
+		}
+
+		parentIdx, ok := topLevelTasks[task.Parent]
+		if !ok {
+			raisedTasks = append(raisedTasks, task)
+			continue
+		}
+		raisedTasks[parentIdx].Children = append(raisedTasks[parentIdx].Children, task)
+	}
+
 	return raisedTasks
 }
 
-func statusFilter(list *QTasks, status string) {
+func filterTasks(list []*QTask, filter string) []*QTask {
+	switch filter {
+	case QCompletedFilter:
+		return statusFilter(list, QCompletedStatus)
+	case QNeedsActionFilter:
+		return statusFilter(list, QNeedsActionStatus)
+	case QOverdueFilter:
+		return whereTasks(list, []QTaskFilter{Overdue()})
+	case QDeletedFilter:
+		return deletedFilter(list)
+	}
+
+	return list
+}
+
+func whereTasks(list []*QTask, filters []QTaskFilter) []*QTask {
+	if len(filters) == 0 {
+		return list
+	}
+
+	matchingTasks := make([]*QTask, 0)
+
+	for _, task := range list {
+		matches := true
+		for _, filter := range filters {
+			if !filter(task) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			matchingTasks = append(matchingTasks, task)
+		}
+	}
+
+	return matchingTasks
+}
+
+func deletedFilter(list []*QTask) []*QTask {
+	deletedTasks := make([]*QTask, 0)
+
+	for _, task := range list {
+		if task.Deleted {
+			deletedTasks = append(deletedTasks, task)
+		}
+	}
+
+	return deletedTasks
+}
+
+func sortTasks(list []*QTask, sort string) {
+	if len(list) < 2 {
+		return
+	}
+
+	switch sort {
+	case QPositionSort:
+		positionalSort(list)
+	case QLatestFirstSort:
+		chronologicalSort(list)
+	case QOldestFirstSort:
+		reverseChronologicalSort(list)
+	case QPrioritySort:
+		prioritySort(list)
+	}
+}
+
+func statusFilter(list []*QTask, status string) []*QTask {
 	matchingTasks := make([]*QTask, 0)
 
-	for _, task := range list.Items {
+	for _, task := range list {
 		if task.Status == status {
 			matchingTasks = append(matchingTasks, task)
 		}
 	}
 
-	list.Items = matchingTasks
+	return matchingTasks
 }
 
 func positionalSort(list []*QTask) {
@@ -56,15 +137,31 @@ func positionalSort(list []*QTask) {
 	}
 }
 
+// Most urgent first, tasks of equal priority keep their order
+func prioritySort(list []*QTask) {
+	length := len(list)
+
+	for i := 1; i < length; i++ {
+		j := i
+		for j > 0 && list[j].Priority() > list[j-1].Priority() {
+			list[j], list[j-1] = list[j-1], list[j]
+			j -= 1
+		}
+	}
+}
+
 func chronologicalSort(list []*QTask) {
 	length := len(list)
 
 	for i := 1; i < length; i++ {
 		j := i
+		for j > 0 {
+			tj0, _ := time.Parse(time.RFC3339, list[j-1].Updated)
+			tj1, _ := time.Parse(time.RFC3339, list[j].Updated)
+			if !tj1.After(tj0) {
+				break
+			}
 
-		tj0, _ := time.Parse(time.RFC3339, list[j-1].Updated)
-		tj1, _ := time.Parse(time.RFC3339, list[j].Updated)
-		for j > 0 && tj1.After(tj0) {
 			list[j], list[j-1] = list[j-1], list[j]
 			j -= 1
 		}
@@ -0,0 +1,92 @@
+package tasq
+
+import (
+	"strings"
+)
+
+// Higher values are more urgent, the zero value means no priority
+type QPriority int
+
+const (
+	QNoPriority QPriority = iota
+	QP3
+	QP2
+	QP1
+	QP0
+)
+
+const priorityMetaKey = "priority"
+
+var priorityPrefixes = []struct {
+	prefix   string
+	priority QPriority
+}{
+	{"!!!", QP0},
+	{"!!", QP1},
+	{"(A)", QP0},
+	{"(B)", QP1},
+	{"(C)", QP2},
+	{"(D)", QP3},
+}
+
+func (priority QPriority) String() string {
+	switch priority {
+	case QP0:
+		return "P0"
+	case QP1:
+		return "P1"
+	case QP2:
+		return "P2"
+	case QP3:
+		return "P3"
+	}
+
+	return ""
+}
+
+func ParsePriority(value string) QPriority {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "P0", "0":
+		return QP0
+	case "P1", "1":
+		return QP1
+	case "P2", "2":
+		return QP2
+	case "P3", "3":
+		return QP3
+	}
+
+	return QNoPriority
+}
+
+// Read from the priority field of the notes metadata, falling back
+// to a !!! or !! prefix, or a todo.txt style (A) to (D) prefix, of the title
+func (task *QTask) Priority() QPriority {
+	if meta, err := task.Meta(); err == nil {
+		if priority := ParsePriority(meta[priorityMetaKey]); priority != QNoPriority {
+			return priority
+		}
+	}
+
+	title := strings.TrimSpace(task.Title)
+	for _, prefix := range priorityPrefixes {
+		if strings.HasPrefix(title, prefix.prefix) {
+			return prefix.priority
+		}
+	}
+
+	return QNoPriority
+}
+
+// Stores the priority in the notes metadata, QNoPriority removes it
+func (task *QTask) SetPriority(priority QPriority) error {
+	return task.SetMeta(priorityMetaKey, priority.String())
+}
+
+// Keeps tasks at least as urgent as the given priority
+func MinPriority(priority QPriority) QTaskFilter {
+	return func(task *QTask) bool {
+		taskPriority := task.Priority()
+		return taskPriority != QNoPriority && taskPriority >= priority
+	}
+}
@@ -0,0 +1,424 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	QDaily   = "DAILY"
+	QWeekly  = "WEEKLY"
+	QMonthly = "MONTHLY"
+	QYearly  = "YEARLY"
+
+	rrulePrefix = "RRULE:"
+
+	recurrenceMetaKey = "rrule"
+
+	// Upper bound on days searched for the next occurrence
+	maxRecurrenceSearch = 4 * 366
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Subset of RFC 5545 recurrence rules, stored as the rrule field
+// of the meta footer of a task, e.g. rrule: FREQ=WEEKLY;BYDAY=MO,TH
+type QRecurrence struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+
+	// Zero when the rule does not end at a date
+	Until time.Time
+
+	// Occurrences left including the current one, zero means unlimited
+	Count int
+}
+
+func ParseRecurrence(rule string) (*QRecurrence, error) {
+	rule = strings.TrimSpace(rule)
+	if len(rule) >= len(rrulePrefix) && strings.EqualFold(rule[:len(rrulePrefix)], rrulePrefix) {
+		rule = rule[len(rrulePrefix):]
+	}
+
+	recurrence := &QRecurrence{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+
+		pair := strings.SplitN(part, "=", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("tasq: malformed recurrence rule part %q", part)
+		}
+		key, value := strings.ToUpper(pair[0]), strings.ToUpper(pair[1])
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch value {
+			case QDaily, QWeekly, QMonthly, QYearly:
+				recurrence.Freq = value
+			default:
+				return nil, fmt.Errorf("tasq: unsupported recurrence frequency %q", value)
+			}
+		case "INTERVAL":
+			recurrence.Interval, err = strconv.Atoi(value)
+			if err == nil && recurrence.Interval < 1 {
+				err = fmt.Errorf("tasq: recurrence interval must be positive")
+			}
+		case "COUNT":
+			recurrence.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			recurrence.Until, err = parseUntil(value)
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("tasq: unsupported recurrence day %q", day)
+				}
+				recurrence.ByDay = append(recurrence.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, convErr := strconv.Atoi(day)
+				if convErr != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("tasq: invalid recurrence month day %q", day)
+				}
+				recurrence.ByMonthDay = append(recurrence.ByMonthDay, monthDay)
+			}
+		default:
+			return nil, fmt.Errorf("tasq: unsupported recurrence rule part %q", key)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if recurrence.Freq == "" {
+		return nil, fmt.Errorf("tasq: recurrence rule is missing FREQ")
+	}
+
+	return recurrence, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if len(value) >= 8 {
+		until, err := time.Parse("20060102", value[:8])
+		if err == nil {
+			return until, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("tasq: invalid recurrence end date %q", value)
+}
+
+func (recurrence *QRecurrence) String() string {
+	parts := []string{"FREQ=" + recurrence.Freq}
+	if recurrence.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(recurrence.Interval))
+	}
+
+	if len(recurrence.ByDay) > 0 {
+		days := make([]string, 0)
+		for _, weekday := range recurrence.ByDay {
+			for name, day := range rruleWeekdays {
+				if day == weekday {
+					days = append(days, name)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(recurrence.ByMonthDay) > 0 {
+		days := make([]string, 0)
+		for _, day := range recurrence.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if recurrence.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(recurrence.Count))
+	}
+	if !recurrence.Until.IsZero() {
+		parts = append(parts, "UNTIL="+recurrence.Until.Format("20060102"))
+	}
+
+	return rrulePrefix + strings.Join(parts, ";")
+}
+
+// Returns the date of the first occurrence after the given date,
+// false once the rule has run out of occurrences
+func (recurrence *QRecurrence) Next(after time.Time) (time.Time, bool) {
+	if recurrence.Count == 1 {
+		return time.Time{}, false
+	}
+
+	year, month, day := after.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	var next time.Time
+	found := false
+	switch {
+	case recurrence.Freq == QDaily:
+		next, found = start.AddDate(0, 0, recurrence.Interval), true
+	case recurrence.Freq == QWeekly && len(recurrence.ByDay) == 0:
+		next, found = start.AddDate(0, 0, 7*recurrence.Interval), true
+	case recurrence.Freq == QYearly:
+		for years := recurrence.Interval; years <= 8*recurrence.Interval && !found; years += recurrence.Interval {
+			next = time.Date(year+years, month, day, 0, 0, 0, 0, time.UTC)
+			found = next.Day() == day
+		}
+	default:
+		for days := 1; days <= maxRecurrenceSearch*recurrence.Interval && !found; days++ {
+			next = start.AddDate(0, 0, days)
+			found = recurrence.matches(start, next)
+		}
+	}
+
+	if !found || (!recurrence.Until.IsZero() && next.After(recurrence.Until)) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+func (recurrence *QRecurrence) matches(start, date time.Time) bool {
+	switch recurrence.Freq {
+	case QWeekly:
+		if weeksBetween(start, date)%recurrence.Interval != 0 {
+			return false
+		}
+
+		for _, weekday := range recurrence.ByDay {
+			if date.Weekday() == weekday {
+				return true
+			}
+		}
+		return false
+
+	case QMonthly:
+		months := (date.Year()-start.Year())*12 + int(date.Month()-start.Month())
+		if months%recurrence.Interval != 0 {
+			return false
+		}
+
+		monthDays := recurrence.ByMonthDay
+		if len(monthDays) == 0 {
+			monthDays = []int{start.Day()}
+		}
+
+		lastDay := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		for _, monthDay := range monthDays {
+			if monthDay < 0 {
+				monthDay = lastDay + monthDay + 1
+			}
+			if date.Day() == monthDay {
+				return true
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+// Weeks start on Monday as in RFC 5545
+func weeksBetween(start, date time.Time) int {
+	weekStart := func(t time.Time) time.Time {
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset)
+	}
+
+	return int(weekStart(date).Sub(weekStart(start)).Hours()/24) / 7
+}
+
+// Reads the rrule field of the meta footer, falling back
+// to a RRULE line written in the notes by older versions
+func (task *QTask) Recurrence() (*QRecurrence, error) {
+	footer, err := splitMeta(task.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := parseMeta(footer.lines)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := meta.String(recurrenceMetaKey)
+	if rule == "" {
+		var ok bool
+		if rule, ok = recurrenceRule(footer.before + footer.after); !ok {
+			return nil, nil
+		}
+	}
+
+	return ParseRecurrence(rule)
+}
+
+// Stores the rule in the meta footer, passing nil removes it.
+// A RRULE line left by older versions is removed either way
+func (task *QTask) SetRecurrence(recurrence *QRecurrence) error {
+	footer, err := splitMeta(task.Notes)
+	if err != nil {
+		return err
+	}
+
+	meta, err := parseMeta(footer.lines)
+	if err != nil {
+		return err
+	}
+
+	if recurrence == nil {
+		delete(meta, recurrenceMetaKey)
+	} else {
+		meta[recurrenceMetaKey] = strings.TrimPrefix(recurrence.String(), rrulePrefix)
+	}
+
+	footer.before = removeRecurrenceRule(footer.before)
+	footer.after = removeRecurrenceRule(footer.after)
+	task.Notes = joinMeta(footer, meta)
+	return nil
+}
+
+// Inserts the next occurrence of every completed recurring task right after it,
+// the rule moves to the new task so each occurrence is only created once.
+// A task that fails does not stop the others, the error reports every failure
+func (svc *QService) ProcessRecurrences(ctx context.Context) ([]*QTask, error) {
+	completed, err := svc.AllTasks(ctx, &QAllTasksOptions{Filter: QCompletedFilter})
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*QTask, 0)
+	report := &QBulkReport{}
+	for _, task := range flattenTasks(completed) {
+		next, err := task.nextOccurrence(ctx)
+		if next != nil {
+			created = append(created, next)
+		}
+		if next != nil || err != nil {
+			report.Results = append(report.Results, &QTaskResult{Task: task, Result: next, Err: err})
+		}
+	}
+
+	return created, report.Err()
+}
+
+func (task *QTask) nextOccurrence(ctx context.Context) (*QTask, error) {
+	if task.Status != QCompletedStatus {
+		return nil, nil
+	}
+
+	recurrence, err := task.Recurrence()
+	if err != nil || recurrence == nil {
+		return nil, err
+	}
+
+	after, err := Time(task.Due)
+	if err != nil && task.Completed != nil {
+		after, err = Time(*task.Completed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	due, ok := recurrence.Next(after)
+	if !ok {
+		return nil, task.clearRecurrence(ctx)
+	}
+	if recurrence.Count > 0 {
+		recurrence.Count--
+	}
+
+	occurrence := &tasks.Task{
+		Title:  task.Title,
+		Notes:  task.Notes,
+		Status: QNeedsActionStatus,
+		Due:    dueDate(due),
+	}
+	if err := (&QTask{Task: occurrence}).SetRecurrence(recurrence); err != nil {
+		return nil, err
+	}
+
+	call := task.ctx.service.Insert(task.ctx.tasklistid, &QTask{Task: occurrence}).Context(ctx).Previous(task.Id)
+	if task.Parent != "" {
+		call.Parent(task.Parent)
+	}
+
+	created, err := call.Do()
+	if err != nil {
+		return nil, err
+	}
+	created.Tasklist = task.Tasklist
+
+	// A task keeping its rule would get another occurrence on the next run
+	if err := task.clearRecurrence(ctx); err != nil {
+		return nil, created.rollback(ctx, err)
+	}
+
+	return created, nil
+}
+
+func (task *QTask) clearRecurrence(ctx context.Context) error {
+	patch := &tasks.Task{Notes: task.Notes}
+	if err := (&QTask{Task: patch}).SetRecurrence(nil); err != nil {
+		return err
+	}
+	if patch.Notes == "" {
+		patch.NullFields = []string{"Notes"}
+	}
+
+	_, err := task.ctx.service.Patch(task.ctx.tasklistid, task.Id, &QTask{Task: patch}).Context(ctx).Do()
+	return err
+}
+
+func recurrenceRule(notes string) (string, bool) {
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) >= len(rrulePrefix) && strings.EqualFold(line[:len(rrulePrefix)], rrulePrefix) {
+			return line, true
+		}
+	}
+
+	return "", false
+}
+
+// Removes RRULE lines leaving every other byte of the text untouched
+func removeRecurrenceRule(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := make([]string, 0, len(lines))
+	removed := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) >= len(rrulePrefix) && strings.EqualFold(trimmed[:len(rrulePrefix)], rrulePrefix) {
+			removed = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	if !removed {
+		return text
+	}
+
+	return strings.Join(kept, "\n")
+}
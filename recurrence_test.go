@@ -0,0 +1,151 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"net/http"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	cases := []struct {
+		rule       string
+		recurrence *QRecurrence
+		err        bool
+	}{
+		{
+			rule:       "RRULE:FREQ=WEEKLY;BYDAY=MO,TH",
+			recurrence: &QRecurrence{Freq: QWeekly, Interval: 1, ByDay: []time.Weekday{time.Monday, time.Thursday}},
+		},
+		{
+			rule:       "freq=daily;interval=3;count=5",
+			recurrence: &QRecurrence{Freq: QDaily, Interval: 3, Count: 5},
+		},
+		{
+			rule:       "FREQ=MONTHLY;BYMONTHDAY=1,-1;UNTIL=20241231T000000Z",
+			recurrence: &QRecurrence{Freq: QMonthly, Interval: 1, ByMonthDay: []int{1, -1}, Until: time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		},
+		{rule: "", err: true},
+		{rule: "INTERVAL=2", err: true},
+		{rule: "FREQ=HOURLY", err: true},
+		{rule: "FREQ=DAILY;INTERVAL=0", err: true},
+		{rule: "FREQ=WEEKLY;BYDAY=XX", err: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", err: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=0", err: true},
+		{rule: "FREQ=DAILY;UNTIL=tomorrow", err: true},
+		{rule: "FREQ=DAILY;BYSETPOS=1", err: true},
+		{rule: "FREQ", err: true},
+	}
+
+	for _, c := range cases {
+		recurrence, err := ParseRecurrence(c.rule)
+		if (err != nil) != c.err {
+			t.Errorf("%q: unexpected error %v", c.rule, err)
+			continue
+		}
+		if !c.err && !reflect.DeepEqual(recurrence, c.recurrence) {
+			t.Errorf("%q: got %+v, want %+v", c.rule, recurrence, c.recurrence)
+		}
+	}
+}
+
+func TestRecurrenceString(t *testing.T) {
+	for _, rule := range []string{
+		"RRULE:FREQ=DAILY",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+		"RRULE:FREQ=YEARLY;UNTIL=20301231",
+	} {
+		recurrence, err := ParseRecurrence(rule)
+		if err != nil {
+			t.Fatalf("%q: %v", rule, err)
+		}
+		if recurrence.String() != rule {
+			t.Errorf("%q: got %q", rule, recurrence.String())
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name  string
+		rule  string
+		after time.Time
+		next  time.Time
+		ok    bool
+	}{
+		{"daily", "FREQ=DAILY", date(2024, time.January, 31), date(2024, time.February, 1), true},
+		{"daily interval", "FREQ=DAILY;INTERVAL=3", date(2024, time.February, 27), date(2024, time.March, 1), true},
+		{"time of day is ignored", "FREQ=DAILY", time.Date(2024, time.January, 1, 23, 30, 0, 0, time.UTC), date(2024, time.January, 2), true},
+		{"weekly", "FREQ=WEEKLY", date(2024, time.January, 1), date(2024, time.January, 8), true},
+		{"byday later this week", "FREQ=WEEKLY;BYDAY=MO,TH", date(2024, time.January, 1), date(2024, time.January, 4), true},
+		{"byday next week", "FREQ=WEEKLY;BYDAY=MO,TH", date(2024, time.January, 4), date(2024, time.January, 8), true},
+		{"byday skips weeks", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", date(2024, time.January, 4), date(2024, time.January, 15), true},
+		{"byday sunday ends the week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", date(2024, time.January, 1), date(2024, time.January, 7), true},
+		{"monthly skips short months", "FREQ=MONTHLY", date(2024, time.January, 31), date(2024, time.March, 31), true},
+		{"bymonthday 31 skips short months", "FREQ=MONTHLY;BYMONTHDAY=31", date(2024, time.April, 30), date(2024, time.May, 31), true},
+		{"last day of a leap february", "FREQ=MONTHLY;BYMONTHDAY=-1", date(2024, time.January, 31), date(2024, time.February, 29), true},
+		{"last day of february", "FREQ=MONTHLY;BYMONTHDAY=-1", date(2023, time.January, 31), date(2023, time.February, 28), true},
+		{"monthly interval", "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=15", date(2024, time.January, 15), date(2024, time.March, 15), true},
+		{"year end", "FREQ=MONTHLY;BYMONTHDAY=1", date(2024, time.December, 1), date(2025, time.January, 1), true},
+		{"yearly", "FREQ=YEARLY", date(2024, time.March, 1), date(2025, time.March, 1), true},
+		{"yearly leap day", "FREQ=YEARLY", date(2024, time.February, 29), date(2028, time.February, 29), true},
+		{"last occurrence", "FREQ=DAILY;COUNT=1", date(2024, time.January, 1), time.Time{}, false},
+		{"count left", "FREQ=DAILY;COUNT=2", date(2024, time.January, 1), date(2024, time.January, 2), true},
+		{"past until", "FREQ=WEEKLY;UNTIL=20240105", date(2024, time.January, 1), time.Time{}, false},
+		{"until day included", "FREQ=WEEKLY;UNTIL=20240108", date(2024, time.January, 1), date(2024, time.January, 8), true},
+	}
+
+	for _, c := range cases {
+		recurrence, err := ParseRecurrence(c.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		next, ok := recurrence.Next(c.after)
+		if ok != c.ok || !next.Equal(c.next) {
+			t.Errorf("%s: got %v, %v, want %v, %v", c.name, next, ok, c.next, c.ok)
+		}
+	}
+}
+
+func TestNextOccurrenceRemovedWhenRuleIsKept(t *testing.T) {
+	deleted := ""
+	svc := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			fmt.Fprint(w, `{"id":"next"}`)
+		case http.MethodPatch:
+			w.WriteHeader(http.StatusInternalServerError)
+		case http.MethodDelete:
+			deleted = path.Base(r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	task := &QTask{
+		Task: &tasks.Task{
+			Id:     "task",
+			Status: QCompletedStatus,
+			Due:    "2024-01-01T00:00:00.000Z",
+			Notes:  "```meta\nrrule: FREQ=DAILY\n```",
+		},
+		ctx: &QTaskCallContext{service: svc.Tasks, tasklistid: "list"},
+	}
+
+	created, err := task.nextOccurrence(context.Background())
+	if err == nil || created != nil {
+		t.Fatalf("got %v, %v, want an error", created, err)
+	}
+	if deleted != "next" {
+		t.Errorf("the new occurrence was not deleted")
+	}
+}
@@ -0,0 +1,329 @@
+package tasq
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/googleapi"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	QDefaultPollInterval = 5 * time.Minute
+	QDefaultMaxLate      = time.Hour
+
+	// Fired reminders older than this are forgotten
+	firedRetention = 30 * 24 * time.Hour
+)
+
+type QReminder struct {
+	Task   *QTask
+	Offset time.Duration
+	At     time.Time
+}
+
+// Delivers reminders, a reminder whose delivery fails is tried again after the next poll
+type QNotifier interface {
+	Notify(ctx context.Context, reminder *QReminder) error
+}
+
+type QNotifierFunc func(ctx context.Context, reminder *QReminder) error
+
+func (notify QNotifierFunc) Notify(ctx context.Context, reminder *QReminder) error {
+	return notify(ctx, reminder)
+}
+
+type QRemindersOptions struct {
+	// Offsets from midnight of the due day, 9*time.Hour fires at 09:00
+	// on the due day and 9*time.Hour-24*time.Hour at 09:00 the day before
+	Offsets []time.Duration
+
+	// Location of the due day midnight, defaults to time.Local
+	Location *time.Location
+
+	// Defaults to QDefaultPollInterval
+	PollInterval time.Duration
+
+	// Reminders missed by more than this, e.g. while not running,
+	// are skipped, defaults to QDefaultMaxLate
+	MaxLate time.Duration
+
+	// File recording fired reminders so they do not fire again after
+	// a restart, fired reminders are only kept in memory when empty
+	FiredPath string
+
+	// Called with errors from polling and delivery, Run keeps going
+	OnError func(err error)
+}
+
+type QReminders struct {
+	svc      *QService
+	notifier QNotifier
+	opts     QRemindersOptions
+
+	lists map[string]*reminderList
+
+	// Guards pending and fired, which Pending reads while Run is going
+	mu      sync.Mutex
+	pending []*QReminder
+	fired   map[string]time.Time
+}
+
+type reminderList struct {
+	etag  string
+	items []*QTask
+}
+
+func (svc *QService) Reminders(notifier QNotifier, opts *QRemindersOptions) (*QReminders, error) {
+	reminders := &QReminders{
+		svc:      svc,
+		notifier: notifier,
+		lists:    make(map[string]*reminderList),
+		fired:    make(map[string]time.Time),
+	}
+	if opts != nil {
+		reminders.opts = *opts
+	}
+	if reminders.opts.Location == nil {
+		reminders.opts.Location = time.Local
+	}
+	if reminders.opts.PollInterval <= 0 {
+		reminders.opts.PollInterval = QDefaultPollInterval
+	}
+	if reminders.opts.MaxLate <= 0 {
+		reminders.opts.MaxLate = QDefaultMaxLate
+	}
+
+	if err := reminders.loadFired(); err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+// Polls for changes and fires reminders until the context is done
+func (reminders *QReminders) Run(ctx context.Context) error {
+	nextPoll := time.Now()
+
+	for {
+		if !time.Now().Before(nextPoll) {
+			if err := reminders.poll(ctx); err != nil {
+				reminders.fail(err)
+			}
+			nextPoll = time.Now().Add(reminders.opts.PollInterval)
+		}
+
+		reminders.fire(ctx, time.Now())
+
+		wait := time.Until(nextPoll)
+		reminders.mu.Lock()
+		if len(reminders.pending) > 0 {
+			if untilNext := time.Until(reminders.pending[0].At); untilNext < wait {
+				wait = untilNext
+			}
+		}
+		reminders.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Reminders waiting to fire, earliest first
+func (reminders *QReminders) Pending() []*QReminder {
+	reminders.mu.Lock()
+	defer reminders.mu.Unlock()
+
+	pending := make([]*QReminder, len(reminders.pending))
+	copy(pending, reminders.pending)
+	return pending
+}
+
+func (reminders *QReminders) poll(ctx context.Context) error {
+	tasklists, err := reminders.svc.Tasklists.List().Context(ctx).doAll()
+	if err != nil {
+		return err
+	}
+
+	lists := make(map[string]*reminderList)
+	for _, tasklist := range tasklists {
+		list, ok := reminders.lists[tasklist.Id]
+		if !ok {
+			list = &reminderList{}
+		}
+
+		if err := reminders.refresh(ctx, tasklist.Id, list); err != nil {
+			return err
+		}
+		lists[tasklist.Id] = list
+	}
+	reminders.lists = lists
+
+	reminders.schedule()
+	return nil
+}
+
+// Only refetches the tasks of a tasklist when its etag has changed
+func (reminders *QReminders) refresh(ctx context.Context, tasklistid string, list *reminderList) error {
+	call := reminders.svc.Tasks.List(tasklistid).Context(ctx).Filter(QNeedsActionFilter)
+	if list.etag != "" {
+		call.IfNoneMatch(list.etag)
+	}
+
+	first, err := call.Do()
+	if googleapi.IsNotModified(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	items := first.Items
+	if first.NextPageToken != "" {
+		items, err = reminders.svc.Tasks.List(tasklistid).Context(ctx).Filter(QNeedsActionFilter).doAll()
+		if err != nil {
+			return err
+		}
+	}
+
+	list.etag = first.Etag
+	list.items = items
+	return nil
+}
+
+// Rebuilds the pending reminders from the latest tasks,
+// so changed due dates and completed tasks are picked up
+func (reminders *QReminders) schedule() {
+	reminders.mu.Lock()
+	defer reminders.mu.Unlock()
+
+	pending := make([]*QReminder, 0)
+
+	for _, list := range reminders.lists {
+		for _, task := range flattenTasks(list.items) {
+			due, err := Time(task.Due)
+			if err != nil || task.Status == QCompletedStatus {
+				continue
+			}
+
+			midnight := DateOf(due.UTC()).In(reminders.opts.Location)
+			for _, offset := range reminders.opts.Offsets {
+				reminder := &QReminder{
+					Task:   task,
+					Offset: offset,
+					At:     midnight.Add(offset),
+				}
+				if _, fired := reminders.fired[reminder.key()]; !fired {
+					pending = append(pending, reminder)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].At.Before(pending[j].At)
+	})
+	reminders.pending = pending
+}
+
+// Notifiers and OnError are called without holding the lock so they may call Pending
+func (reminders *QReminders) fire(ctx context.Context, now time.Time) {
+	reminders.mu.Lock()
+	due := make([]*QReminder, 0)
+	remaining := make([]*QReminder, 0)
+	for _, reminder := range reminders.pending {
+		if reminder.At.After(now) {
+			remaining = append(remaining, reminder)
+		} else {
+			due = append(due, reminder)
+		}
+	}
+	reminders.pending = remaining
+	reminders.mu.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	fired := make([]*QReminder, 0)
+	for _, reminder := range due {
+		if now.Sub(reminder.At) <= reminders.opts.MaxLate {
+			if err := reminders.notifier.Notify(ctx, reminder); err != nil {
+				reminders.fail(err)
+				continue
+			}
+		}
+		fired = append(fired, reminder)
+	}
+
+	if len(fired) == 0 {
+		return
+	}
+
+	reminders.mu.Lock()
+	for _, reminder := range fired {
+		reminders.fired[reminder.key()] = reminder.At
+	}
+	err := reminders.saveFired(now)
+	reminders.mu.Unlock()
+
+	if err != nil {
+		reminders.fail(err)
+	}
+}
+
+func (reminders *QReminders) fail(err error) {
+	if reminders.opts.OnError != nil {
+		reminders.opts.OnError(err)
+	}
+}
+
+// Includes the due date so a rescheduled task is reminded again
+func (reminder *QReminder) key() string {
+	return fmt.Sprintf("%s/%s/%s/%d", reminder.Task.ctx.tasklistid, reminder.Task.Id, reminder.Task.Due, reminder.Offset)
+}
+
+func (reminders *QReminders) loadFired() error {
+	if reminders.opts.FiredPath == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(reminders.opts.FiredPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &reminders.fired)
+}
+
+// The caller holds the lock
+func (reminders *QReminders) saveFired(now time.Time) error {
+	for key, at := range reminders.fired {
+		if now.Sub(at) > firedRetention {
+			delete(reminders.fired, key)
+		}
+	}
+
+	if reminders.opts.FiredPath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(reminders.fired)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(reminders.opts.FiredPath, data)
+}
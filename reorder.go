@@ -0,0 +1,129 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"sort"
+)
+
+// Moves top-level tasks into the given order, tasks left out of
+// orderedIDs keep their relative order after the ordered ones
+func (tasks *QTasks) Reorder(ctx context.Context, orderedIDs []string) ([]*QTask, error) {
+	return tasks.reorder(ctx, "", tasks.Items, orderedIDs)
+}
+
+// Same as Reorder for the subtasks of parent
+func (tasks *QTasks) ReorderChildren(ctx context.Context, parent string, orderedIDs []string) ([]*QTask, error) {
+	for _, task := range flattenTasks(tasks.Items) {
+		if task.Id == parent {
+			return tasks.reorder(ctx, parent, task.Children, orderedIDs)
+		}
+	}
+
+	return nil, fmt.Errorf("tasq: parent task %q not found", parent)
+}
+
+// Tasks on the longest run already in the desired relative order stay put,
+// every other task is moved right after its desired predecessor
+func (tasks *QTasks) reorder(ctx context.Context, parent string, siblings []*QTask, orderedIDs []string) ([]*QTask, error) {
+	current := make([]*QTask, len(siblings))
+	copy(current, siblings)
+	sort.SliceStable(current, func(i, j int) bool {
+		return current[i].Position < current[j].Position
+	})
+
+	index := make(map[string]int)
+	for i, task := range current {
+		index[task.Id] = i
+	}
+
+	desired := make([]*QTask, 0, len(current))
+	listed := make(map[string]bool)
+	for _, id := range orderedIDs {
+		i, ok := index[id]
+		if !ok {
+			return nil, fmt.Errorf("tasq: task %q is not a sibling being reordered", id)
+		}
+		if listed[id] {
+			return nil, fmt.Errorf("tasq: task %q listed more than once", id)
+		}
+
+		listed[id] = true
+		desired = append(desired, current[i])
+	}
+	for _, task := range current {
+		if !listed[task.Id] {
+			desired = append(desired, task)
+		}
+	}
+
+	positions := make([]int, len(desired))
+	for i, task := range desired {
+		positions[i] = index[task.Id]
+	}
+	stay := longestIncreasing(positions)
+
+	moved := make([]*QTask, 0)
+	config := tasks.ctx.service.config
+	for i, task := range desired {
+		if stay[i] {
+			continue
+		}
+
+		call := tasks.ctx.service.Move(tasks.ctx.tasklistid, task.Id).Context(ctx)
+		if parent != "" {
+			call.Parent(parent)
+		}
+		if i > 0 {
+			call.Previous(desired[i-1].Id)
+		}
+
+		result, err := withRetry(ctx, config.limiter, func() (*QTask, error) {
+			return call.Do()
+		})
+		if err != nil {
+			return moved, err
+		}
+
+		task.Task = result.Task
+		task.base = result.base
+		moved = append(moved, task)
+	}
+
+	copy(siblings, desired)
+	return moved, nil
+}
+
+// Marks the members of one longest strictly increasing subsequence, O(n log n)
+func longestIncreasing(values []int) []bool {
+	// tails[k] is the index of the smallest tail of an increasing run of length k+1
+	tails := make([]int, 0, len(values))
+	previous := make([]int, len(values))
+
+	for i, value := range values {
+		k := sort.Search(len(tails), func(k int) bool {
+			return values[tails[k]] >= value
+		})
+
+		previous[i] = -1
+		if k > 0 {
+			previous[i] = tails[k-1]
+		}
+
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	members := make([]bool, len(values))
+	if len(tails) == 0 {
+		return members
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+		members[i] = true
+	}
+
+	return members
+}
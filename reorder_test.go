@@ -0,0 +1,86 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLongestIncreasing(t *testing.T) {
+	cases := []struct {
+		name    string
+		values  []int
+		members []bool
+	}{
+		{"empty", []int{}, []bool{}},
+		{"single", []int{0}, []bool{true}},
+		{"sorted", []int{0, 1, 2, 3}, []bool{true, true, true, true}},
+		{"reversed", []int{3, 2, 1, 0}, []bool{false, false, false, true}},
+		{"first moved last", []int{1, 2, 3, 0}, []bool{true, true, true, false}},
+		{"last moved first", []int{3, 0, 1, 2}, []bool{false, true, true, true}},
+		{"one moved back", []int{0, 2, 3, 1, 4}, []bool{true, true, true, false, true}},
+		{"two runs", []int{2, 3, 0, 1, 4}, []bool{false, false, true, true, true}},
+	}
+
+	for _, c := range cases {
+		if members := longestIncreasing(c.values); !reflect.DeepEqual(members, c.members) {
+			t.Errorf("%s: got %v, want %v", c.name, members, c.members)
+		}
+	}
+}
+
+func TestReorder(t *testing.T) {
+	cases := []struct {
+		name    string
+		ordered []string
+		moves   []string
+	}{
+		{"empty order", nil, []string{}},
+		{"already sorted", []string{"a", "b", "c", "d"}, []string{}},
+		{"partial order already sorted", []string{"a", "b"}, []string{}},
+		{"reversed", []string{"d", "c", "b", "a"}, []string{"d after", "c after d", "b after c"}},
+		{"first displaced to the end", []string{"b", "c", "d", "a"}, []string{"a after d"}},
+		{"last displaced to the top", []string{"d"}, []string{"d after"}},
+		{"one displaced in the middle", []string{"a", "c", "b", "d"}, []string{"c after a"}},
+	}
+
+	for _, c := range cases {
+		moves := make([]string, 0)
+		svc := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			id := path.Base(strings.TrimSuffix(r.URL.Path, "/move"))
+			moves = append(moves, strings.TrimSpace(id+" after "+r.URL.Query().Get("previous")))
+			fmt.Fprintf(w, `{"id":%q}`, id)
+		})
+
+		list := &QTasks{ctx: &QTaskCallContext{service: svc.Tasks, tasklistid: "list"}}
+		for i, id := range []string{"a", "b", "c", "d"} {
+			list.Items = append(list.Items, &QTask{Task: &tasks.Task{Id: id, Position: fmt.Sprint(i)}})
+		}
+
+		moved, err := list.Reorder(context.Background(), c.ordered)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(moves, c.moves) {
+			t.Errorf("%s: got moves %q, want %q", c.name, moves, c.moves)
+		}
+		if len(moved) != len(c.moves) {
+			t.Errorf("%s: reported %d moved tasks, want %d", c.name, len(moved), len(c.moves))
+		}
+	}
+}
+
+func TestReorderInvalid(t *testing.T) {
+	list := &QTasks{Items: []*QTask{{Task: &tasks.Task{Id: "a"}}, {Task: &tasks.Task{Id: "b"}}}}
+
+	for _, ordered := range [][]string{{"a", "a"}, {"c"}} {
+		if _, err := list.Reorder(context.Background(), ordered); err == nil {
+			t.Errorf("%q: expected an error", ordered)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package tasq
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"sort"
+	"time"
+)
+
+// Picks the new due date of the i-th of n tasks being rescheduled
+type QReschedulePolicy func(i, n int, task *QTask, today QDate) QDate
+
+func MoveToToday() QReschedulePolicy {
+	return func(i, n int, task *QTask, today QDate) QDate {
+		return today
+	}
+}
+
+// Shifts the current due date, tasks without one are shifted from today
+func ShiftBy(days int) QReschedulePolicy {
+	return func(i, n int, task *QTask, today QDate) QDate {
+		due, err := Time(task.Due)
+		if err != nil {
+			return today.AddDays(days)
+		}
+
+		return DateOf(due.UTC()).AddDays(days)
+	}
+}
+
+// Spreads tasks evenly over the next Monday to Friday days starting today,
+// tasks due earliest are given the earliest days
+func SpreadOver(workingDays int) QReschedulePolicy {
+	if workingDays < 1 {
+		workingDays = 1
+	}
+
+	return func(i, n int, task *QTask, today QDate) QDate {
+		return nextWorkingDay(today, i*workingDays/n)
+	}
+}
+
+// Matches tasks needing action due before today, taken from DefaultDateParser,
+// a task due today is not overdue yet
+func Overdue() QTaskFilter {
+	return func(task *QTask) bool {
+		if task.Status != QNeedsActionStatus {
+			return false
+		}
+
+		due, err := Time(task.Due)
+		if err != nil {
+			return false
+		}
+
+		today := DefaultDateParser.today()
+		return DateOf(due.UTC()).In(time.UTC).Before(today.In(time.UTC))
+	}
+}
+
+// Pushes the due date forward by whole days from the current due date,
+// or from today when the task is overdue or has no due date. The patch
+// carries the etag, so a due date changed remotely is not snoozed twice
+// when optimistic concurrency is enabled
+func (task *QTask) Snooze(ctx context.Context, days int) (*QTask, error) {
+	from := DefaultDateParser.today()
+	if due, err := Time(task.Due); err == nil && DateOf(due.UTC()).In(time.UTC).After(from.In(time.UTC)) {
+		from = DateOf(due.UTC())
+	}
+
+	return task.patchDue(ctx, from.AddDays(days))
+}
+
+// Patches the due date of tasks matching the filter, or of overdue tasks
+// when the filter is nil. Today is taken from DefaultDateParser
+func (tasks *QTasks) Reschedule(ctx context.Context, filter QTaskFilter, policy QReschedulePolicy) *QBulkReport {
+	if filter == nil {
+		filter = Overdue()
+	}
+
+	selected := make([]*QTask, 0)
+	for _, task := range flattenTasks(tasks.Items) {
+		if filter(task) {
+			selected = append(selected, task)
+		}
+	}
+
+	// Earliest due first so spreading keeps the original order
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Due < selected[j].Due
+	})
+
+	today := DefaultDateParser.today()
+	dates := make(map[*QTask]QDate)
+	for i, task := range selected {
+		dates[task] = policy(i, len(selected), task, today)
+	}
+
+	return tasks.bulk(ctx, selected, func(ctx context.Context, task *QTask) (*QTask, error) {
+		return task.patchDue(ctx, dates[task])
+	})
+}
+
+func (task *QTask) patchDue(ctx context.Context, date QDate) (*QTask, error) {
+	patch := &tasks.Task{Due: dueDate(date.In(time.UTC)), Etag: task.Etag}
+	return task.ctx.service.Patch(task.ctx.tasklistid, task.Id, &QTask{Task: patch}).Context(ctx).Do()
+}
+
+// The n-th working day from date, counting date itself when it is one
+func nextWorkingDay(date QDate, n int) QDate {
+	for isWeekend(date) {
+		date = date.AddDays(1)
+	}
+
+	for n > 0 {
+		date = date.AddDays(1)
+		if !isWeekend(date) {
+			n--
+		}
+	}
+
+	return date
+}
+
+func isWeekend(date QDate) bool {
+	weekday := date.Weekday()
+	return weekday == time.Saturday || weekday == time.Sunday
+}
@@ -0,0 +1,202 @@
+package tasq
+
+import (
+	"golang.org/x/net/context"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	titleMatchWeight = 3.0
+	notesMatchWeight = 1.0
+	prefixMatchRatio = 0.5
+
+	// Boost of up to double the score halves for every week since the task was last updated
+	recencyHalfLife = 7 * 24 * time.Hour
+)
+
+type QSearchResult struct {
+	Task  *QTask
+	Score float64
+}
+
+type searchEntry struct {
+	task   *QTask
+	title  []string
+	notes  []string
+	update time.Time
+}
+
+type searchCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	built   time.Time
+	entries []*searchEntry
+}
+
+func (svc *QService) EnableSearchCache(ttl time.Duration) {
+	svc.search = &searchCache{ttl: ttl}
+}
+
+func (svc *QService) InvalidateSearchCache() {
+	if svc.search == nil {
+		return
+	}
+
+	svc.search.mu.Lock()
+	svc.search.entries = nil
+	svc.search.mu.Unlock()
+}
+
+func (svc *QService) Search(ctx context.Context, query string) ([]*QSearchResult, error) {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return []*QSearchResult{}, nil
+	}
+
+	entries, err := svc.searchEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	results := make([]*QSearchResult, 0)
+	for _, entry := range entries {
+		score := entry.score(terms)
+		if score == 0 {
+			continue
+		}
+
+		if !entry.update.IsZero() {
+			age := now.Sub(entry.update)
+			if age < 0 {
+				age = 0
+			}
+			score *= 1 + math.Exp2(-float64(age)/float64(recencyHalfLife))
+		}
+
+		results = append(results, &QSearchResult{
+			Task:  entry.task,
+			Score: score,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results, nil
+}
+
+// The index is skipped when responses are cached, as revalidating
+// them with their etags is cheap and never serves stale tasks
+func (svc *QService) searchEntries(ctx context.Context) ([]*searchEntry, error) {
+	cache := svc.search
+	if cache == nil || svc.config.cache != nil {
+		return svc.buildSearchEntries(ctx)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.entries != nil && time.Since(cache.built) < cache.ttl {
+		return cache.entries, nil
+	}
+
+	entries, err := svc.buildSearchEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.entries = entries
+	cache.built = time.Now()
+	return entries, nil
+}
+
+func (svc *QService) buildSearchEntries(ctx context.Context) ([]*searchEntry, error) {
+	all, err := svc.AllTasks(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*searchEntry, 0)
+	var add func(task *QTask)
+	add = func(task *QTask) {
+		updated, _ := task.Time()
+		entries = append(entries, &searchEntry{
+			task:   task,
+			title:  tokenize(task.Title),
+			notes:  tokenize(task.PlainNotes()),
+			update: updated,
+		})
+
+		for _, child := range task.Children {
+			add(child)
+		}
+	}
+	for _, task := range all {
+		add(task)
+	}
+
+	return entries, nil
+}
+
+// Every term has to match either the title or the notes,
+// a zero score means the entry did not match
+func (entry *searchEntry) score(terms []string) float64 {
+	total := 0.0
+
+	for _, term := range terms {
+		titleScore := matchTokens(entry.title, term) * titleMatchWeight
+		notesScore := matchTokens(entry.notes, term) * notesMatchWeight
+		if titleScore == 0 && notesScore == 0 {
+			return 0
+		}
+
+		if titleScore > notesScore {
+			total += titleScore
+		} else {
+			total += notesScore
+		}
+	}
+
+	return total
+}
+
+func matchTokens(tokens []string, term string) float64 {
+	best := 0.0
+
+	for _, token := range tokens {
+		if token == term {
+			return 1
+		}
+		if strings.HasPrefix(token, term) {
+			best = prefixMatchRatio
+		}
+	}
+
+	return best
+}
+
+func foldText(text string) string {
+	foldTransformer := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(foldTransformer, text)
+	if err != nil {
+		folded = text
+	}
+
+	return strings.ToLower(folded)
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(foldText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,128 @@
+package tasq
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"time"
+)
+
+const (
+	QCompletedStatus   = "completed"
+	QNeedsActionStatus = "needsAction"
+)
+
+type QStatusOption int
+
+const (
+	// Apply the same status change to every subtask
+	QCascadeChildren QStatusOption = iota + 1
+
+	// Complete the parent once its last subtask is completed,
+	// or reopen a completed parent when a subtask is reopened
+	QCascadeParent
+)
+
+func (task *QTask) Complete(ctx context.Context, opts ...QStatusOption) (*QTask, error) {
+	return task.setStatus(ctx, QCompletedStatus, opts)
+}
+
+func (task *QTask) Reopen(ctx context.Context, opts ...QStatusOption) (*QTask, error) {
+	return task.setStatus(ctx, QNeedsActionStatus, opts)
+}
+
+func (task *QTask) Toggle(ctx context.Context, opts ...QStatusOption) (*QTask, error) {
+	if task.Status == QCompletedStatus {
+		return task.Reopen(ctx, opts...)
+	}
+
+	return task.Complete(ctx, opts...)
+}
+
+func (task *QTask) setStatus(ctx context.Context, status string, opts []QStatusOption) (*QTask, error) {
+	updated, err := task.patchStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasStatusOption(opts, QCascadeChildren) {
+		children, err := task.subtasks(ctx)
+		if err != nil {
+			return updated, err
+		}
+
+		for _, child := range children {
+			if child.Status == status {
+				updated.Children = append(updated.Children, child)
+				continue
+			}
+
+			updatedChild, err := child.patchStatus(ctx, status)
+			if err != nil {
+				return updated, err
+			}
+			updated.Children = append(updated.Children, updatedChild)
+		}
+	} else {
+		updated.Children = task.Children
+	}
+
+	if hasStatusOption(opts, QCascadeParent) && task.Parent != "" {
+		if err := task.cascadeParent(ctx, status); err != nil {
+			return updated, err
+		}
+	}
+
+	return updated, nil
+}
+
+func (task *QTask) cascadeParent(ctx context.Context, status string) error {
+	items, err := task.ctx.service.List(task.ctx.tasklistid).Context(ctx).ShowHidden(true).doAll()
+	if err != nil {
+		return err
+	}
+
+	for _, parent := range items {
+		if parent.Id != task.Parent {
+			continue
+		}
+		if parent.Status == status {
+			return nil
+		}
+
+		if status == QCompletedStatus {
+			for _, sibling := range parent.Children {
+				if sibling.Id != task.Id && sibling.Status != QCompletedStatus {
+					return nil
+				}
+			}
+		}
+
+		_, err := parent.patchStatus(ctx, status)
+		return err
+	}
+
+	return nil
+}
+
+func (task *QTask) patchStatus(ctx context.Context, status string) (*QTask, error) {
+	// Sent as If-Match when optimistic concurrency is enabled
+	patch := &tasks.Task{Status: status, Etag: task.Etag}
+	if status == QCompletedStatus {
+		completed := time.Now().UTC().Format(time.RFC3339)
+		patch.Completed = &completed
+	} else {
+		patch.NullFields = []string{"Completed"}
+	}
+
+	return task.ctx.service.Patch(task.ctx.tasklistid, task.Id, &QTask{Task: patch}).Context(ctx).Do()
+}
+
+func hasStatusOption(opts []QStatusOption, option QStatusOption) bool {
+	for _, opt := range opts {
+		if opt == option {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package tasq
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+	"regexp"
+	"strings"
+)
+
+var hashtagPattern = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_-]+)`)
+
+// Hashtags in the title and notes, normalized to lower case without
+// accents, in order of first appearance
+func (task *QTask) Tags() []string {
+	tags := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, text := range []string{task.Title, task.Notes} {
+		for _, match := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+			tag := normalizeTag(match[2])
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+func (task *QTask) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, taskTag := range task.Tags() {
+		if taskTag == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Appends the hashtag to the title unless the task already has it
+func (task *QTask) AddTag(ctx context.Context, tag string) (*QTask, error) {
+	tag = strings.TrimPrefix(tag, "#")
+	if task.HasTag(tag) {
+		return task, nil
+	}
+
+	title := strings.TrimSpace(task.Title + " #" + tag)
+	return task.patchText(ctx, title, task.Notes)
+}
+
+// Removes every occurrence of the hashtag from the title and notes
+func (task *QTask) RemoveTag(ctx context.Context, tag string) (*QTask, error) {
+	if !task.HasTag(tag) {
+		return task, nil
+	}
+
+	return task.patchText(ctx, removeTag(task.Title, tag), removeTag(task.Notes, tag))
+}
+
+func (task *QTask) patchText(ctx context.Context, title, notes string) (*QTask, error) {
+	// The etag lets optimistic concurrency reject the patch when the task changed remotely
+	patch := &tasks.Task{Title: title, Notes: notes, Etag: task.Etag}
+	if notes == "" {
+		patch.NullFields = []string{"Notes"}
+	}
+
+	return task.ctx.service.Patch(task.ctx.tasklistid, task.Id, &QTask{Task: patch}).Context(ctx).Do()
+}
+
+func HasTag(tag string) QTaskFilter {
+	return func(task *QTask) bool {
+		return task.HasTag(tag)
+	}
+}
+
+// Maps each tag to the tasks carrying it across every tasklist
+func (svc *QService) TagIndex(ctx context.Context) (map[string][]*QTask, error) {
+	all, err := svc.AllTasks(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string][]*QTask)
+	for _, task := range flattenTasks(all) {
+		for _, tag := range task.Tags() {
+			index[tag] = append(index[tag], task)
+		}
+	}
+
+	return index, nil
+}
+
+func normalizeTag(tag string) string {
+	return foldText(strings.TrimPrefix(tag, "#"))
+}
+
+// Cuts out each occurrence of the hashtag with at most one space or tab
+// next to it, the rest of the text is kept byte for byte
+func removeTag(text, tag string) string {
+	tag = normalizeTag(tag)
+
+	var removed strings.Builder
+	last := 0
+	for _, match := range hashtagPattern.FindAllStringSubmatchIndex(text, -1) {
+		if normalizeTag(text[match[4]:match[5]]) != tag {
+			continue
+		}
+
+		// The hashtag starts with the # right before the tag
+		start, end := match[4]-1, match[5]
+		if end < len(text) && isTagSeparator(text[end]) {
+			end++
+		} else if start > last && isTagSeparator(text[start-1]) {
+			start--
+		}
+
+		removed.WriteString(text[last:start])
+		last = end
+	}
+	removed.WriteString(text[last:])
+
+	return removed.String()
+}
+
+func isTagSeparator(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,63 @@
+package tasq
+
+import (
+	"google.golang.org/api/tasks/v1"
+	"reflect"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	task := &QTask{Task: &tasks.Task{
+		Title: "Send invoice #Billing #Q4",
+		Notes: "see issue#12\n#café #billing\n# heading",
+	}}
+
+	want := []string{"billing", "q4", "cafe"}
+	if tags := task.Tags(); !reflect.DeepEqual(tags, want) {
+		t.Errorf("got %v, want %v", tags, want)
+	}
+}
+
+func TestRemoveTag(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		tag  string
+		want string
+	}{
+		{"end of title", "Send invoice #billing", "billing", "Send invoice"},
+		{"start of title", "#billing Send invoice", "billing", "Send invoice"},
+		{"middle of title", "Send #billing invoice", "#billing", "Send invoice"},
+		{"only the tag", "#billing", "billing", ""},
+		{"case and accents", "Pay #Café", "cafe", "Pay"},
+		{"other tags kept", "#q4 #billing #q4-review", "billing", "#q4 #q4-review"},
+		{"repeated tag", "#billing #billing done", "billing", "done"},
+		{"longer tag kept", "#billing-q4 #billing", "billing", "#billing-q4"},
+		{"not a tag", "issue#billing", "billing", "issue#billing"},
+		{"missing tag", "  indented\n\n", "billing", "  indented\n\n"},
+		{
+			name: "indented notes",
+			text: "\n  - call  back #billing\n\t#billing\tsend  receipt\n    keep   this\n\n",
+			tag:  "billing",
+			want: "\n  - call  back\n\tsend  receipt\n    keep   this\n\n",
+		},
+		{
+			name: "multiple spaces",
+			text: "a  #billing  b\nc\t\t#billing",
+			tag:  "billing",
+			want: "a   b\nc\t",
+		},
+		{
+			name: "tag alone on a line",
+			text: "first\n#billing\nlast",
+			tag:  "billing",
+			want: "first\n\nlast",
+		},
+	}
+
+	for _, c := range cases {
+		if removed := removeTag(c.text, c.tag); removed != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, removed, c.want)
+		}
+	}
+}
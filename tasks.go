@@ -5,6 +5,8 @@ import (
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"google.golang.org/api/tasks/v1"
+	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -17,7 +19,9 @@ type QTask struct {
 	*tasks.Task
 
 	ctx      *QTaskCallContext
+	base     *tasks.Task
 	Children []*QTask
+	Tasklist *QTaskList
 }
 
 func (task *QTask) InitNewService(tokenString []byte) error {
@@ -31,7 +35,7 @@ func (task *QTask) Delete() error {
 }
 
 func (task *QTask) Insert(tasklistid string) (*QTask, error) {
-	return task.ctx.service.Insert(task.ctx.tasklistid, task).Do()
+	return task.ctx.service.Insert(tasklistid, task).Do()
 }
 
 func (task *QTask) MoveToParent(parent string) (*QTask, error) {
@@ -63,11 +67,15 @@ func (task *QTask) Refresh() error {
 	entityTag := task.Etag
 
 	updated, err := task.ctx.service.Get(task.ctx.tasklistid, id).IfNoneMatch(entityTag).Do()
+	if googleapi.IsNotModified(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
 	task.Task = updated.Task
+	task.base = updated.base
 	return nil
 }
 
@@ -104,15 +112,23 @@ func (tasks *QTasks) Refresh() error {
 	entityTag := tasks.Etag
 
 	updated, err := tasks.ctx.service.List(tasks.ctx.tasklistid).IfNoneMatch(entityTag).Do()
+	if googleapi.IsNotModified(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
 	tasks.Tasks = updated.Tasks
+	tasks.Items = updated.Items
 	return nil
 }
 
-type QTasksService struct{ *tasks.TasksService }
+type QTasksService struct {
+	*tasks.TasksService
+
+	config *serviceConfig
+}
 
 func newQTasksService(tokenString []byte) (*QTasksService, error) {
 	ctx := context.Background()
@@ -127,7 +143,10 @@ func newQTasksService(tokenString []byte) (*QTasksService, error) {
 		return &QTasksService{}, err
 	}
 
-	return &QTasksService{TasksService: service.Tasks}, nil
+	return &QTasksService{
+		TasksService: service.Tasks,
+		config:       &serviceConfig{},
+	}, nil
 }
 
 type QTasksClearCall struct {
@@ -152,6 +171,14 @@ func (call *QTasksClearCall) Context(ctx context.Context) *QTasksClearCall {
 }
 
 func (call *QTasksClearCall) Do(opts ...googleapi.CallOption) error {
+	if plan := call.ctx.service.config.plan; plan != nil {
+		plan.record(&QPlannedCall{
+			Method:   QTasksClearMethod,
+			Tasklist: call.ctx.tasklistid,
+		})
+		return nil
+	}
+
 	return call.TasksClearCall.Do(opts...)
 }
 
@@ -163,7 +190,9 @@ func (call *QTasksClearCall) Fields(s ...googleapi.Field) *QTasksClearCall {
 type QTasksDeleteCall struct {
 	*tasks.TasksDeleteCall
 
-	ctx *QTaskCallContext
+	ctx     *QTaskCallContext
+	context context.Context
+	taskid  string
 }
 
 func (tasks *QTasksService) Delete(tasklistid string, taskid string) *QTasksDeleteCall {
@@ -173,16 +202,37 @@ func (tasks *QTasksService) Delete(tasklistid string, taskid string) *QTasksDele
 			service:    tasks,
 			tasklistid: tasklistid,
 		},
+		taskid: taskid,
 	}
 }
 
 func (call *QTasksDeleteCall) Context(ctx context.Context) *QTasksDeleteCall {
 	call.TasksDeleteCall.Context(ctx)
+	call.context = ctx
 	return call
 }
 
 func (call *QTasksDeleteCall) Do(opts ...googleapi.CallOption) error {
-	return call.TasksDeleteCall.Do(opts...)
+	if plan := call.ctx.service.config.plan; plan != nil {
+		plan.record(&QPlannedCall{
+			Method:   QTasksDeleteMethod,
+			Tasklist: call.ctx.tasklistid,
+			Task:     call.taskid,
+		})
+		return nil
+	}
+
+	journal := call.ctx.service.config.journal
+	entry, err := journal.beforeTaskChange(call.context, call.ctx.service, QTasksDeleteMethod, call.ctx.tasklistid, call.taskid)
+	if err != nil {
+		return err
+	}
+
+	if err := call.TasksDeleteCall.Do(opts...); err != nil {
+		return err
+	}
+
+	return journal.append(entry)
 }
 
 func (call *QTasksDeleteCall) Fields(s ...googleapi.Field) *QTasksDeleteCall {
@@ -193,7 +243,10 @@ func (call *QTasksDeleteCall) Fields(s ...googleapi.Field) *QTasksDeleteCall {
 type QTasksGetCall struct {
 	*tasks.TasksGetCall
 
-	ctx *QTaskCallContext
+	ctx         *QTaskCallContext
+	taskid      string
+	fields      string
+	ifNoneMatch bool
 }
 
 func (tasks *QTasksService) Get(tasklistid string, taskid string) *QTasksGetCall {
@@ -203,6 +256,7 @@ func (tasks *QTasksService) Get(tasklistid string, taskid string) *QTasksGetCall
 			service:    tasks,
 			tasklistid: tasklistid,
 		},
+		taskid: taskid,
 	}
 }
 
@@ -212,26 +266,32 @@ func (call *QTasksGetCall) Context(ctx context.Context) *QTasksGetCall {
 }
 
 func (call *QTasksGetCall) Do(opts ...googleapi.CallOption) (*QTask, error) {
-	result, err := call.TasksGetCall.Do(opts...)
+	result, err := call.cachedDo(opts...)
 	return &QTask{
 		Task: result,
-		ctx:  call.ctx}, err
+		ctx:  call.ctx,
+		base: snapshotTask(result)}, err
 }
 
 func (call *QTasksGetCall) Fields(s ...googleapi.Field) *QTasksGetCall {
 	call.TasksGetCall.Fields(s...)
+	call.fields = string(googleapi.CombineFields(s))
 	return call
 }
 
 func (call *QTasksGetCall) IfNoneMatch(entityTag string) *QTasksGetCall {
 	call.TasksGetCall.IfNoneMatch(entityTag)
+	call.ifNoneMatch = true
 	return call
 }
 
 type QTasksInsertCall struct {
 	*tasks.TasksInsertCall
 
-	ctx *QTaskCallContext
+	ctx      *QTaskCallContext
+	task     *QTask
+	parent   string
+	previous string
 }
 
 func (tasks *QTasksService) Insert(tasklistid string, task *QTask) *QTasksInsertCall {
@@ -241,6 +301,7 @@ func (tasks *QTasksService) Insert(tasklistid string, task *QTask) *QTasksInsert
 			service:    tasks,
 			tasklistid: tasklistid,
 		},
+		task: task,
 	}
 }
 
@@ -250,10 +311,33 @@ func (call *QTasksInsertCall) Context(ctx context.Context) *QTasksInsertCall {
 }
 
 func (call *QTasksInsertCall) Do(opts ...googleapi.CallOption) (*QTask, error) {
+	if plan := call.ctx.service.config.plan; plan != nil {
+		result := plan.synthesizeTask(&QPlannedCall{
+			Method:   QTasksInsertMethod,
+			Tasklist: call.ctx.tasklistid,
+			Parent:   call.parent,
+			Previous: call.previous,
+			Body:     call.task.Task,
+		}, call.task.Task)
+		return &QTask{
+			Task: result,
+			ctx:  call.ctx,
+			base: snapshotTask(result)}, nil
+	}
+
 	result, err := call.TasksInsertCall.Do(opts...)
+	if err == nil {
+		err = call.ctx.service.config.journal.append(&QJournalEntry{
+			Method:   QTasksInsertMethod,
+			Tasklist: call.ctx.tasklistid,
+			Task:     result.Id,
+		})
+	}
+
 	return &QTask{
 		Task: result,
-		ctx:  call.ctx}, err
+		ctx:  call.ctx,
+		base: snapshotTask(result)}, err
 }
 
 func (call *QTasksInsertCall) Fields(s ...googleapi.Field) *QTasksInsertCall {
@@ -263,20 +347,25 @@ func (call *QTasksInsertCall) Fields(s ...googleapi.Field) *QTasksInsertCall {
 
 func (call *QTasksInsertCall) Parent(parent string) *QTasksInsertCall {
 	call.TasksInsertCall.Parent(parent)
+	call.parent = parent
 	return call
 }
 
 func (call *QTasksInsertCall) Previous(previous string) *QTasksInsertCall {
 	call.TasksInsertCall.Previous(previous)
+	call.previous = previous
 	return call
 }
 
 type QTasksListCall struct {
 	*tasks.TasksListCall
 
-	ctx    *QTaskCallContext
-	filter string
-	sort   string
+	ctx         *QTaskCallContext
+	filter      string
+	sort        string
+	where       []QTaskFilter
+	params      url.Values
+	ifNoneMatch bool
 }
 
 func (tasks *QTasksService) List(tasklistid string) *QTasksListCall {
@@ -286,16 +375,19 @@ func (tasks *QTasksService) List(tasklistid string) *QTasksListCall {
 			service:    tasks,
 			tasklistid: tasklistid,
 		},
+		params: url.Values{},
 	}
 }
 
 func (call *QTasksListCall) CompletedMax(completedMax string) *QTasksListCall {
 	call.TasksListCall.CompletedMax(completedMax)
+	call.params.Set("completedMax", completedMax)
 	return call
 }
 
 func (call *QTasksListCall) CompletedMin(completedMin string) *QTasksListCall {
 	call.TasksListCall.CompletedMin(completedMin)
+	call.params.Set("completedMin", completedMin)
 	return call
 }
 
@@ -304,70 +396,87 @@ func (call *QTasksListCall) Context(ctx context.Context) *QTasksListCall {
 	return call
 }
 
-// TODO: Filter for deleted tasks
 func (call *QTasksListCall) Do(opts ...googleapi.CallOption) (*QTasks, error) {
-	switch call.filter {
-	case QOverdueFilter:
-		call.DueMax(time.Now().Format(time.RFC3339))
-	case QCompletedFilter:
-		call.ShowHidden(true).ShowCompleted(true)
-	case QNeedsActionFilter:
-		call.ShowHidden(false).ShowCompleted(false)
-	}
+	call.applyFilter()
 
-	result, err := call.TasksListCall.Do(opts...)
+	result, err := call.cachedDo(opts...)
 	if err != nil {
 		return &QTasks{}, err
 	}
 
-	list := &QTasks{
+	return &QTasks{
 		Tasks: result,
 		ctx:   call.ctx,
-	}
-	if len(list.Items) > 0 {
-		switch call.filter {
-		case QCompletedFilter:
-			statusFilter(list, "completed")
-		case QNeedsActionFilter:
-			statusFilter(list, "needsAction")
+		Items: call.postDo(result.Items),
+	}, nil
+}
+
+func (call *QTasksListCall) doAll(opts ...googleapi.CallOption) ([]*QTask, error) {
+	call.applyFilter()
+	call.MaxResults(100)
+
+	items := make([]*tasks.Task, 0)
+	for {
+		result, err := call.cachedDo(opts...)
+		if err != nil {
+			return nil, err
 		}
 
-		if len(list.Items) > 1 {
-			switch call.sort {
-			case QPositionSort:
-				positionalSort(list.Items)
-			case QLatestFirstSort:
-				chronologicalSort(list.Items)
-			case QOldestFirstSort:
-				reverseChronologicalSort(list.Items)
-			}
+		items = append(items, result.Items...)
+		if result.NextPageToken == "" {
+			break
 		}
+		call.PageToken(result.NextPageToken)
+	}
+
+	return call.postDo(items), nil
+}
+
+func (call *QTasksListCall) applyFilter() {
+	switch call.filter {
+	case QOverdueFilter:
+		// Computed from the clock so it is kept out of the cache key
+		call.TasksListCall.DueMax(time.Now().Format(time.RFC3339))
+	case QCompletedFilter:
+		call.ShowHidden(true).ShowCompleted(true)
+	case QNeedsActionFilter:
+		call.ShowHidden(false).ShowCompleted(false)
+	case QDeletedFilter:
+		call.ShowDeleted(true).ShowHidden(true)
 	}
+}
 
+func (call *QTasksListCall) postDo(result []*tasks.Task) []*QTask {
 	items := make([]*QTask, 0)
-	for _, item := range result.Items {
+	for _, item := range result {
 		items = append(items, &QTask{
 			Task: item,
 			ctx:  call.ctx,
+			base: snapshotTask(item),
 		})
 	}
-	list.Items = raiseTasks(items)
 
-	return list, err
+	items = raiseTasks(whereTasks(filterTasks(items, call.filter), call.where))
+	sortTasks(items, call.sort)
+
+	return items
 }
 
 func (call *QTasksListCall) DueMax(dueMax string) *QTasksListCall {
 	call.TasksListCall.DueMax(dueMax)
+	call.params.Set("dueMax", dueMax)
 	return call
 }
 
 func (call *QTasksListCall) DueMin(dueMin string) *QTasksListCall {
 	call.TasksListCall.DueMin(dueMin)
+	call.params.Set("dueMin", dueMin)
 	return call
 }
 
 func (call *QTasksListCall) Fields(s ...googleapi.Field) *QTasksListCall {
 	call.TasksListCall.Fields(s...)
+	call.params.Set("fields", string(googleapi.CombineFields(s)))
 	return call
 }
 
@@ -378,31 +487,37 @@ func (call *QTasksListCall) Filter(filter string) *QTasksListCall {
 
 func (call *QTasksListCall) IfNoneMatch(entityTag string) *QTasksListCall {
 	call.TasksListCall.IfNoneMatch(entityTag)
+	call.ifNoneMatch = true
 	return call
 }
 
 func (call *QTasksListCall) MaxResults(maxResults int64) *QTasksListCall {
 	call.TasksListCall.MaxResults(maxResults)
+	call.params.Set("maxResults", strconv.FormatInt(maxResults, 10))
 	return call
 }
 
 func (call *QTasksListCall) PageToken(pageToken string) *QTasksListCall {
 	call.TasksListCall.PageToken(pageToken)
+	call.params.Set("pageToken", pageToken)
 	return call
 }
 
 func (call *QTasksListCall) ShowCompleted(showCompleted bool) *QTasksListCall {
 	call.TasksListCall.ShowCompleted(showCompleted)
+	call.params.Set("showCompleted", strconv.FormatBool(showCompleted))
 	return call
 }
 
 func (call *QTasksListCall) ShowDeleted(showDeleted bool) *QTasksListCall {
 	call.TasksListCall.ShowDeleted(showDeleted)
+	call.params.Set("showDeleted", strconv.FormatBool(showDeleted))
 	return call
 }
 
 func (call *QTasksListCall) ShowHidden(showHidden bool) *QTasksListCall {
 	call.TasksListCall.ShowHidden(showHidden)
+	call.params.Set("showHidden", strconv.FormatBool(showHidden))
 	return call
 }
 
@@ -411,15 +526,26 @@ func (call *QTasksListCall) Sort(sort string) *QTasksListCall {
 	return call
 }
 
+// Only keeps tasks matching every filter passed to Where
+func (call *QTasksListCall) Where(filter QTaskFilter) *QTasksListCall {
+	call.where = append(call.where, filter)
+	return call
+}
+
 func (call *QTasksListCall) UpdateMin(updatedMin string) *QTasksListCall {
 	call.TasksListCall.UpdatedMin(updatedMin)
+	call.params.Set("updatedMin", updatedMin)
 	return call
 }
 
 type QTasksMoveCall struct {
 	*tasks.TasksMoveCall
 
-	ctx *QTaskCallContext
+	ctx      *QTaskCallContext
+	context  context.Context
+	taskid   string
+	parent   string
+	previous string
 }
 
 func (tasks *QTasksService) Move(tasklistid string, taskid string) *QTasksMoveCall {
@@ -429,19 +555,46 @@ func (tasks *QTasksService) Move(tasklistid string, taskid string) *QTasksMoveCa
 			service:    tasks,
 			tasklistid: tasklistid,
 		},
+		taskid: taskid,
 	}
 }
 
 func (call *QTasksMoveCall) Context(ctx context.Context) *QTasksMoveCall {
 	call.TasksMoveCall.Context(ctx)
+	call.context = ctx
 	return call
 }
 
 func (call *QTasksMoveCall) Do(opts ...googleapi.CallOption) (*QTask, error) {
+	if plan := call.ctx.service.config.plan; plan != nil {
+		result := plan.synthesizeTask(&QPlannedCall{
+			Method:   QTasksMoveMethod,
+			Tasklist: call.ctx.tasklistid,
+			Task:     call.taskid,
+			Parent:   call.parent,
+			Previous: call.previous,
+		}, nil)
+		return &QTask{
+			Task: result,
+			ctx:  call.ctx,
+			base: snapshotTask(result)}, nil
+	}
+
+	journal := call.ctx.service.config.journal
+	entry, err := journal.beforeTaskChange(call.context, call.ctx.service, QTasksMoveMethod, call.ctx.tasklistid, call.taskid)
+	if err != nil {
+		return &QTask{}, err
+	}
+
 	result, err := call.TasksMoveCall.Do(opts...)
+	if err == nil {
+		err = journal.append(entry)
+	}
+
 	return &QTask{
 		Task: result,
-		ctx:  call.ctx}, err
+		ctx:  call.ctx,
+		base: snapshotTask(result)}, err
 }
 
 func (call *QTasksMoveCall) Fields(s ...googleapi.Field) *QTasksMoveCall {
@@ -451,18 +604,24 @@ func (call *QTasksMoveCall) Fields(s ...googleapi.Field) *QTasksMoveCall {
 
 func (call *QTasksMoveCall) Parent(parent string) *QTasksMoveCall {
 	call.TasksMoveCall.Parent(parent)
+	call.parent = parent
 	return call
 }
 
 func (call *QTasksMoveCall) Previous(previous string) *QTasksMoveCall {
 	call.TasksMoveCall.Previous(previous)
+	call.previous = previous
 	return call
 }
 
 type QTasksPatchCall struct {
 	*tasks.TasksPatchCall
 
-	ctx *QTaskCallContext
+	ctx     *QTaskCallContext
+	context context.Context
+	taskid  string
+	task    *QTask
+	ifMatch string
 }
 
 func (tasks *QTasksService) Patch(tasklistid string, taskid string, task *QTask) *QTasksPatchCall {
@@ -472,19 +631,55 @@ func (tasks *QTasksService) Patch(tasklistid string, taskid string, task *QTask)
 			service:    tasks,
 			tasklistid: tasklistid,
 		},
+		taskid: taskid,
+		task:   task,
 	}
 }
 
 func (call *QTasksPatchCall) Context(ctx context.Context) *QTasksPatchCall {
 	call.TasksPatchCall.Context(ctx)
+	call.context = ctx
 	return call
 }
 
 func (call *QTasksPatchCall) Do(opts ...googleapi.CallOption) (*QTask, error) {
+	if plan := call.ctx.service.config.plan; plan != nil {
+		result := plan.synthesizeTask(&QPlannedCall{
+			Method:   QTasksPatchMethod,
+			Tasklist: call.ctx.tasklistid,
+			Task:     call.taskid,
+			Body:     call.task.Task,
+		}, call.task.Task)
+		return &QTask{
+			Task: result,
+			ctx:  call.ctx,
+			base: snapshotTask(result)}, nil
+	}
+
+	journal := call.ctx.service.config.journal
+	entry, err := journal.beforeTaskChange(call.context, call.ctx.service, QTasksPatchMethod, call.ctx.tasklistid, call.taskid)
+	if err != nil {
+		return &QTask{}, err
+	}
+
+	setIfMatch(call.Header(), call.ctx.service.config, call.ifMatch, call.task.Etag)
+
 	result, err := call.TasksPatchCall.Do(opts...)
+	if isPreconditionFailed(err) {
+		resolved, err := call.ctx.service.resolveConflict(call.context, call.ctx.tasklistid, call.taskid, call.task, err)
+		if err == nil {
+			err = journal.append(entry)
+		}
+		return resolved, err
+	}
+	if err == nil {
+		err = journal.append(entry)
+	}
+
 	return &QTask{
 		Task: result,
-		ctx:  call.ctx}, err
+		ctx:  call.ctx,
+		base: snapshotTask(result)}, err
 }
 
 func (call *QTasksPatchCall) Fields(s ...googleapi.Field) *QTasksPatchCall {
@@ -492,10 +687,19 @@ func (call *QTasksPatchCall) Fields(s ...googleapi.Field) *QTasksPatchCall {
 	return call
 }
 
+func (call *QTasksPatchCall) IfMatch(entityTag string) *QTasksPatchCall {
+	call.ifMatch = entityTag
+	return call
+}
+
 type QTasksUpdateCall struct {
 	*tasks.TasksUpdateCall
 
-	ctx *QTaskCallContext
+	ctx     *QTaskCallContext
+	context context.Context
+	taskid  string
+	task    *QTask
+	ifMatch string
 }
 
 func (tasks *QTasksService) Update(tasklistid string, taskid string, task *QTask) *QTasksUpdateCall {
@@ -505,22 +709,63 @@ func (tasks *QTasksService) Update(tasklistid string, taskid string, task *QTask
 			service:    tasks,
 			tasklistid: tasklistid,
 		},
+		taskid: taskid,
+		task:   task,
 	}
 }
 
 func (call *QTasksUpdateCall) Context(ctx context.Context) *QTasksUpdateCall {
 	call.TasksUpdateCall.Context(ctx)
+	call.context = ctx
 	return call
 }
 
 func (call *QTasksUpdateCall) Do(opts ...googleapi.CallOption) (*QTask, error) {
+	if plan := call.ctx.service.config.plan; plan != nil {
+		result := plan.synthesizeTask(&QPlannedCall{
+			Method:   QTasksUpdateMethod,
+			Tasklist: call.ctx.tasklistid,
+			Task:     call.taskid,
+			Body:     call.task.Task,
+		}, call.task.Task)
+		return &QTask{
+			Task: result,
+			ctx:  call.ctx,
+			base: snapshotTask(result)}, nil
+	}
+
+	journal := call.ctx.service.config.journal
+	entry, err := journal.beforeTaskChange(call.context, call.ctx.service, QTasksUpdateMethod, call.ctx.tasklistid, call.taskid)
+	if err != nil {
+		return &QTask{}, err
+	}
+
+	setIfMatch(call.Header(), call.ctx.service.config, call.ifMatch, call.task.Etag)
+
 	result, err := call.TasksUpdateCall.Do(opts...)
+	if isPreconditionFailed(err) {
+		resolved, err := call.ctx.service.resolveConflict(call.context, call.ctx.tasklistid, call.taskid, call.task, err)
+		if err == nil {
+			err = journal.append(entry)
+		}
+		return resolved, err
+	}
+	if err == nil {
+		err = journal.append(entry)
+	}
+
 	return &QTask{
 		Task: result,
-		ctx:  call.ctx}, err
+		ctx:  call.ctx,
+		base: snapshotTask(result)}, err
 }
 
 func (call *QTasksUpdateCall) Fields(s ...googleapi.Field) *QTasksUpdateCall {
 	call.TasksUpdateCall.Fields(s...)
 	return call
 }
+
+func (call *QTasksUpdateCall) IfMatch(entityTag string) *QTasksUpdateCall {
+	call.ifMatch = entityTag
+	return call
+}
@@ -2,8 +2,10 @@ package tasq
 
 import (
 	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
 	"google.golang.org/api/option"
 	"google.golang.org/api/tasks/v1"
+	"net/http"
 	"time"
 )
 
@@ -22,6 +24,20 @@ type QService struct {
 
 	Tasklists *QTasklistsService
 	Tasks     *QTasksService
+
+	client *http.Client
+	config *serviceConfig
+	search *searchCache
+}
+
+type serviceConfig struct {
+	etags       bool
+	merge       bool
+	concurrency int
+	limiter     *rateLimiter
+	plan        *QPlan
+	journal     *QJournal
+	cache       QCacheStorage
 }
 
 func Init(cfg *QConfig) error {
@@ -29,9 +45,11 @@ func Init(cfg *QConfig) error {
 }
 
 func NewService(tokenString []byte) (*QService, error) {
+	config := &serviceConfig{}
 	tasqService := &QService{
-		Tasklists: &QTasklistsService{},
-		Tasks:     &QTasksService{},
+		Tasklists: &QTasklistsService{config: config},
+		Tasks:     &QTasksService{config: config},
+		config:    config,
 	}
 
 	ctx := context.Background()
@@ -40,7 +58,9 @@ func NewService(tokenString []byte) (*QService, error) {
 		return tasqService, err
 	}
 
-	opt := option.WithTokenSource(tokenSource)
+	tasqService.client = oauth2.NewClient(ctx, tokenSource)
+
+	opt := option.WithHTTPClient(tasqService.client)
 	tasqService.Service, err = tasks.NewService(ctx, opt)
 	if err != nil {
 		return tasqService, err
@@ -48,6 +68,7 @@ func NewService(tokenString []byte) (*QService, error) {
 
 	tasqService.Tasklists.TasklistsService = tasqService.Service.Tasklists
 	tasqService.Tasks.TasksService = tasqService.Service.Tasks
+	tasqService.Tasklists.tasks = tasqService.Tasks
 
 	return tasqService, nil
 }
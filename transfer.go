@@ -0,0 +1,130 @@
+package tasq
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+)
+
+type QRollbackError struct {
+	Err         error
+	RollbackErr error
+}
+
+func (e *QRollbackError) Error() string {
+	return fmt.Sprintf("%v (rollback failed: %v)", e.Err, e.RollbackErr)
+}
+
+func (task *QTask) CopyToList(ctx context.Context, tasklistid string) (*QTask, error) {
+	children, err := task.subtasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return task.copyTree(ctx, tasklistid, "", "", children)
+}
+
+func (task *QTask) MoveToList(ctx context.Context, tasklistid string) (*QTask, error) {
+	if tasklistid == task.ctx.tasklistid {
+		return task, nil
+	}
+
+	children, err := task.subtasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return task.moveToListAfter(ctx, tasklistid, "", children)
+}
+
+// Copies the task with the given subtasks after previous in another tasklist,
+// then deletes the original along with all of its subtasks
+func (task *QTask) moveToListAfter(ctx context.Context, tasklistid, previous string, children []*QTask) (*QTask, error) {
+	copied, err := task.copyTree(ctx, tasklistid, "", previous, children)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleting a task also deletes its subtasks
+	err = task.ctx.service.Delete(task.ctx.tasklistid, task.Id).Context(ctx).Do()
+	if err != nil {
+		return nil, copied.rollback(ctx, err)
+	}
+
+	return copied, nil
+}
+
+// Always fetched, Children may only hold the subtasks matching a filter
+func (task *QTask) subtasks(ctx context.Context) ([]*QTask, error) {
+	if task.Parent != "" {
+		return nil, nil
+	}
+
+	items, err := task.ctx.service.List(task.ctx.tasklistid).Context(ctx).ShowHidden(true).ShowCompleted(true).doAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.Id == task.Id {
+			return item.Children, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (task *QTask) copyTree(ctx context.Context, tasklistid, parent, previous string, children []*QTask) (*QTask, error) {
+	service := task.ctx.service
+
+	call := service.Insert(tasklistid, &QTask{Task: copyTask(task.Task)}).Context(ctx)
+	if parent != "" {
+		call.Parent(parent)
+	}
+	if previous != "" {
+		call.Previous(previous)
+	}
+
+	copied, err := call.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	previousChild := ""
+	for _, child := range children {
+		childCall := service.Insert(tasklistid, &QTask{Task: copyTask(child.Task)}).Context(ctx).Parent(copied.Id)
+		if previousChild != "" {
+			childCall.Previous(previousChild)
+		}
+
+		copiedChild, err := childCall.Do()
+		if err != nil {
+			return nil, copied.rollback(ctx, err)
+		}
+
+		previousChild = copiedChild.Id
+		copied.Children = append(copied.Children, copiedChild)
+	}
+
+	return copied, nil
+}
+
+func (task *QTask) rollback(ctx context.Context, err error) error {
+	rollbackErr := task.ctx.service.Delete(task.ctx.tasklistid, task.Id).Context(ctx).Do()
+	if rollbackErr != nil {
+		return &QRollbackError{Err: err, RollbackErr: rollbackErr}
+	}
+
+	return err
+}
+
+func copyTask(task *tasks.Task) *tasks.Task {
+	return &tasks.Task{
+		Title:     task.Title,
+		Notes:     task.Notes,
+		Status:    task.Status,
+		Due:       task.Due,
+		Completed: task.Completed,
+		Links:     task.Links,
+	}
+}
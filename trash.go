@@ -0,0 +1,93 @@
+package tasq
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/api/tasks/v1"
+)
+
+// Lists the deleted tasks of a tasklist
+func (service *QTasksService) Trash(tasklistid string) *QTasksListCall {
+	return service.List(tasklistid).Filter(QDeletedFilter)
+}
+
+// Un-deletes the task and its deleted subtasks, then moves it back under
+// its original parent and after the task that preceded it, falling back
+// to the top level when the parent no longer exists
+func (task *QTask) Restore(ctx context.Context) (*QTask, error) {
+	service := task.ctx.service
+
+	children := task.Children
+	if children == nil && task.Parent == "" {
+		deleted, err := service.Trash(task.ctx.tasklistid).Context(ctx).doAll()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range deleted {
+			if item.Id == task.Id {
+				children = item.Children
+			}
+		}
+	}
+
+	restored, err := task.undelete(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range children {
+		if !child.Deleted {
+			continue
+		}
+
+		restoredChild, err := child.undelete(ctx)
+		if err != nil {
+			return restored, err
+		}
+		restored.Children = append(restored.Children, restoredChild)
+	}
+
+	items, err := service.List(task.ctx.tasklistid).Context(ctx).ShowHidden(true).doAll()
+	if err != nil {
+		return restored, err
+	}
+
+	parent, siblings := "", items
+	for _, item := range items {
+		if task.Parent != "" && item.Id == task.Parent {
+			parent, siblings = item.Id, item.Children
+		}
+	}
+
+	previous := ""
+	for _, sibling := range siblings {
+		if sibling.Id != task.Id && sibling.Position < task.Position {
+			previous = sibling.Id
+		}
+	}
+
+	call := service.Move(task.ctx.tasklistid, task.Id).Context(ctx)
+	if parent != "" {
+		call.Parent(parent)
+	}
+	if previous != "" {
+		call.Previous(previous)
+	}
+
+	moved, err := call.Do()
+	if err != nil {
+		return restored, err
+	}
+	moved.Children = restored.Children
+
+	return moved, nil
+}
+
+func (task *QTask) undelete(ctx context.Context) (*QTask, error) {
+	patch := &tasks.Task{
+		Deleted:         false,
+		ForceSendFields: []string{"Deleted"},
+	}
+
+	return task.ctx.service.Patch(task.ctx.tasklistid, task.Id, &QTask{Task: patch}).Context(ctx).Do()
+}